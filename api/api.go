@@ -35,7 +35,7 @@ func New(opt *RouterOptions) *gin.Engine {
 		InMemory: opt.InMemory,
 	})
 	router.Static("/media", "./media")
-	apiV1 := router.Group("/v1")
+	apiV1 := router.Group("/v1", handlerV1.TimeoutMiddleware)
 
 	// Category
 	apiV1.GET("/categories", handlerV1.GetAllCategories)
@@ -47,6 +47,7 @@ func New(opt *RouterOptions) *gin.Engine {
 	// Like
 	apiV1.POST("/likes", handlerV1.AuthMiddleware, handlerV1.CreateOrUpdateLike)
 	apiV1.GET("/likes/user-post", handlerV1.AuthMiddleware, handlerV1.GetLike)
+	apiV1.GET("/posts/:id/likes", handlerV1.OptionalAuthMiddleware, handlerV1.GetLikers)
 
 	// User
 	apiV1.POST("/users", handlerV1.AuthMiddleware, handlerV1.CreateUser)
@@ -56,18 +57,66 @@ func New(opt *RouterOptions) *gin.Engine {
 	apiV1.DELETE("/users/:id", handlerV1.AuthMiddleware, handlerV1.DeleteUser)
 
 	// Comment
-	apiV1.GET("/comments", handlerV1.GetAllComment)
-	apiV1.GET("/comments/:id", handlerV1.GetComment)
+	apiV1.GET("/comments", handlerV1.OptionalAuthMiddleware, handlerV1.GetAllComment)
+	apiV1.GET("/comments/:id", handlerV1.OptionalAuthMiddleware, handlerV1.GetComment)
+	apiV1.GET("/comments/:id/replies", handlerV1.OptionalAuthMiddleware, handlerV1.GetCommentReplies)
+	apiV1.POST("/comments/:id/reactions", handlerV1.AuthMiddleware, handlerV1.ReactToComment)
 	apiV1.POST("/comments", handlerV1.AuthMiddleware, handlerV1.CreateComment)
 	apiV1.PUT("/comments/:id", handlerV1.AuthMiddleware, handlerV1.UpdateComment)
 	apiV1.DELETE("/comments/:id", handlerV1.AuthMiddleware, handlerV1.DeleteComment)
 
 	// Post
-	apiV1.GET("/posts", handlerV1.GetAllPost)
-	apiV1.GET("/posts/:id", handlerV1.GetPost)
+	apiV1.GET("/posts", handlerV1.OptionalAuthMiddleware, handlerV1.GetAllPost)
+	apiV1.GET("/posts/:id", handlerV1.OptionalAuthMiddleware, handlerV1.GetPost)
+	apiV1.GET("/@:username/:slug", handlerV1.OptionalAuthMiddleware, handlerV1.GetPostBySlug)
 	apiV1.POST("/posts", handlerV1.AuthMiddleware, handlerV1.CreatePost)
 	apiV1.PUT("/posts/:id", handlerV1.AuthMiddleware, handlerV1.UpdatePost)
 	apiV1.DELETE("/posts/:id", handlerV1.AuthMiddleware, handlerV1.DeletePost)
+	apiV1.POST("/posts/:id/publish", handlerV1.AuthMiddleware, handlerV1.PublishPost)
+	apiV1.POST("/posts/:id/unpublish", handlerV1.AuthMiddleware, handlerV1.UnpublishPost)
+
+	// Clap
+	apiV1.POST("/posts/:id/claps", handlerV1.AuthMiddleware, handlerV1.ClapPost)
+	apiV1.GET("/posts/:id/clappers", handlerV1.OptionalAuthMiddleware, handlerV1.GetClappers)
+	apiV1.GET("/posts/:id/related", handlerV1.OptionalAuthMiddleware, handlerV1.GetRelatedPosts)
+
+	// Stats
+	apiV1.GET("/posts/:id/stats", handlerV1.AuthMiddleware, handlerV1.GetPostStats)
+	apiV1.POST("/posts/:id/read", handlerV1.OptionalAuthMiddleware, handlerV1.ReadPost)
+	apiV1.GET("/me/stats", handlerV1.AuthMiddleware, handlerV1.GetMyStats)
+
+	// Post revision
+	apiV1.GET("/posts/:id/revisions", handlerV1.AuthMiddleware, handlerV1.GetPostRevisions)
+	apiV1.GET("/posts/:id/revisions/:rev", handlerV1.AuthMiddleware, handlerV1.GetPostRevision)
+	apiV1.GET("/posts/:id/revisions/:rev/diff", handlerV1.AuthMiddleware, handlerV1.DiffPostRevision)
+	apiV1.POST("/posts/:id/revisions/:rev/restore", handlerV1.AuthMiddleware, handlerV1.RestorePostRevision)
+
+	// Follow
+	apiV1.POST("/users/:id/follow", handlerV1.AuthMiddleware, handlerV1.FollowUser)
+	apiV1.DELETE("/users/:id/follow", handlerV1.AuthMiddleware, handlerV1.UnfollowUser)
+	apiV1.GET("/users/:id/followers", handlerV1.GetFollowers)
+	apiV1.GET("/users/:id/following", handlerV1.GetFollowing)
+	apiV1.GET("/users/:id/following/categories", handlerV1.GetFollowedCategories)
+	apiV1.POST("/categories/:id/follow", handlerV1.AuthMiddleware, handlerV1.FollowCategory)
+	apiV1.DELETE("/categories/:id/follow", handlerV1.AuthMiddleware, handlerV1.UnfollowCategory)
+
+	// Feed
+	apiV1.GET("/feed", handlerV1.AuthMiddleware, handlerV1.GetFeed)
+
+	// Reading list
+	apiV1.GET("/me/lists", handlerV1.AuthMiddleware, handlerV1.GetMyReadingLists)
+	apiV1.POST("/me/lists", handlerV1.AuthMiddleware, handlerV1.CreateReadingList)
+	apiV1.PUT("/me/lists/:id", handlerV1.AuthMiddleware, handlerV1.UpdateReadingList)
+	apiV1.DELETE("/me/lists/:id", handlerV1.AuthMiddleware, handlerV1.DeleteReadingList)
+	apiV1.POST("/me/lists/:id/posts/:post_id", handlerV1.AuthMiddleware, handlerV1.AddReadingListPost)
+	apiV1.DELETE("/me/lists/:id/posts/:post_id", handlerV1.AuthMiddleware, handlerV1.RemoveReadingListPost)
+	apiV1.GET("/users/:id/lists", handlerV1.OptionalAuthMiddleware, handlerV1.GetUserReadingLists)
+	apiV1.GET("/lists/:id", handlerV1.OptionalAuthMiddleware, handlerV1.GetReadingList)
+	apiV1.GET("/lists/:id/posts", handlerV1.OptionalAuthMiddleware, handlerV1.GetReadingListPosts)
+
+	// Tag
+	apiV1.GET("/tags", handlerV1.GetAllTags)
+	apiV1.GET("/tags/:slug/posts", handlerV1.GetTagPosts)
 
 	// file upload
 	apiV1.POST("/file-upload", handlerV1.AuthMiddleware, handlerV1.UploadFile)
@@ -16,10 +16,13 @@ type CreateCategory struct {
 type GetAllCategoriesResponse struct {
 	Categories []*Category `json:"categories"`
 	Count      int         `json:"count"`
+	NextCursor string      `json:"next_cursor,omitempty"`
 }
 
 type GetAllCategoryParams struct {
-	Limit  int    `json:"limit" binding:"required" default:"10"`
-	Page   int    `json:"page" binding:"required" default:"1"`
-	Search string `json:"search"`
+	Limit     int    `json:"limit" binding:"required" default:"10"`
+	Page      int    `json:"page" binding:"required" default:"1"`
+	Search    string `json:"search"`
+	Cursor    string `json:"cursor"`
+	WithCount bool   `json:"with_count"`
 }
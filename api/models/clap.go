@@ -0,0 +1,26 @@
+package models
+
+type ClapRequest struct {
+	Count int `json:"count" binding:"required,min=1,max=50" default:"1"`
+}
+
+type ClapResponse struct {
+	PostID     int `json:"post_id"`
+	MyClaps    int `json:"my_claps"`
+	ClapsCount int `json:"claps_count"`
+}
+
+type Clapper struct {
+	User  UserProfile `json:"user"`
+	Claps int         `json:"claps"`
+}
+
+type GetAllClappersParams struct {
+	Limit int `json:"limit" binding:"required" default:"10"`
+	Page  int `json:"page" binding:"required" default:"1"`
+}
+
+type GetAllClappersResponse struct {
+	Clappers []*Clapper `json:"clappers"`
+	Count    int        `json:"count"`
+}
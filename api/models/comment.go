@@ -5,13 +5,28 @@ import (
 )
 
 type Comment struct {
-	Id          int          `json:"id" db:"id"`
-	PostId      int          `json:"post_id" db:"post_id"`
-	UserId      int          `json:"user_id" db:"user_id"`
-	Description string      `json:"description" db:"description"`
-	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time    `json:"updated_at" db:"updated_at"`
-	User        *UserProfile `json:"user"`
+	Id          int            `json:"id" db:"id"`
+	PostId      int            `json:"post_id" db:"post_id"`
+	UserId      int            `json:"user_id" db:"user_id"`
+	Description string         `json:"description" db:"description"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at"`
+	User        *UserProfile   `json:"user"`
+	ParentId    *int           `json:"parent_id"`
+	ReplyCount  int            `json:"reply_count"`
+	Deleted     bool           `json:"deleted"`
+	Reactions   map[string]int `json:"reactions"`
+	MyReaction  string         `json:"my_reaction,omitempty"`
+	Replies     []*Comment     `json:"replies,omitempty"`
+}
+
+type CommentReactionRequest struct {
+	Reaction string `json:"reaction" binding:"required,oneof=like love laugh wow sad angry"`
+}
+
+type CommentReactionsResponse struct {
+	Reactions  map[string]int `json:"reactions"`
+	MyReaction string         `json:"my_reaction,omitempty"`
 }
 
 type CommentUser struct {
@@ -25,6 +40,7 @@ type CommentUser struct {
 type CreateComment struct {
 	PostId      int     `json:"post_id" db:"post_id"`
 	Description string `json:"description" db:"description"`
+	ParentId    *int   `json:"parent_id"`
 }
 
 type UpdateComment struct {
@@ -41,9 +57,14 @@ type GetAllCommentsParams struct {
 	UserID     int    `json:"user_id"`
 	PostID     int    `json:"post_id"`
 	SortByDate string `json:"sort_by_date" binding:"required,oneof=asc desc" default:"desc"`
+	Depth      int    `json:"depth" default:"0"`
+	Sort       string `json:"sort" enums:"new,top" default:"new"`
+	Cursor     string `json:"cursor"`
+	WithCount  bool   `json:"with_count"`
 }
 
 type GetAllCommentsResponse struct {
-	Comments []*Comment `json:"comments"`
-	Count    int        `json:"count"`
+	Comments   []*Comment `json:"comments"`
+	Count      int        `json:"count"`
+	NextCursor string     `json:"next_cursor,omitempty"`
 }
@@ -0,0 +1,21 @@
+package models
+
+type GetAllFollowsParams struct {
+	Limit int `json:"limit" binding:"required" default:"10"`
+	Page  int `json:"page" binding:"required" default:"1"`
+}
+
+type GetAllFollowsResponse struct {
+	Users []*UserProfile `json:"users"`
+	Count int            `json:"count"`
+}
+
+type GetFeedParams struct {
+	Limit  int    `json:"limit" default:"10"`
+	Cursor string `json:"cursor"`
+}
+
+type FeedResponse struct {
+	Posts      []*PostSummary `json:"posts"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
@@ -11,3 +11,13 @@ type CreateOrUpdateLikeRequest struct {
 	PostID int64 `json:"post_id" binding:"required"`
 	Status bool  `json:"status"`
 }
+
+type GetAllLikersParams struct {
+	Limit int `json:"limit" binding:"required" default:"10"`
+	Page  int `json:"page" binding:"required" default:"1"`
+}
+
+type GetAllLikersResponse struct {
+	Users []*UserProfile `json:"users"`
+	Count int            `json:"count"`
+}
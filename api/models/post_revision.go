@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+type PostRevision struct {
+	Id            int          `json:"id"`
+	PostId        int          `json:"post_id"`
+	Revision      int          `json:"revision"`
+	EditorId      *int         `json:"editor_id"`
+	Title         string       `json:"title"`
+	Description   string       `json:"description"`
+	ContentFormat string       `json:"content_format"`
+	ImageUrl      string       `json:"image_url"`
+	CategoryId    int          `json:"category_id"`
+	CreatedAt     time.Time    `json:"created_at"`
+	Editor        *UserProfile `json:"editor"`
+}
+
+type GetAllPostRevisionsParams struct {
+	Limit int `json:"limit" binding:"required" default:"10"`
+	Page  int `json:"page" binding:"required" default:"1"`
+}
+
+type GetAllPostRevisionsResponse struct {
+	Revisions []*PostRevision `json:"revisions"`
+	Count     int             `json:"count"`
+}
+
+type DiffLine struct {
+	Op   string `json:"op" enums:"equal,insert,delete"`
+	Text string `json:"text"`
+}
+
+type PostRevisionDiff struct {
+	From        int         `json:"from"`
+	To          int         `json:"to"`
+	Title       []*DiffLine `json:"title"`
+	Description []*DiffLine `json:"description"`
+}
@@ -0,0 +1,42 @@
+package models
+
+type GetPostStatsParams struct {
+	From string `json:"from" example:"2022-11-01"`
+	To   string `json:"to" example:"2022-11-30"`
+}
+
+type PostStatsTotals struct {
+	Views         int            `json:"views"`
+	UniqueReaders int            `json:"unique_readers"`
+	Reads         int            `json:"reads"`
+	Likes         int            `json:"likes"`
+	Comments      int            `json:"comments"`
+	Referrers     map[string]int `json:"referrers,omitempty"`
+}
+
+type PostDailyStats struct {
+	Day string `json:"day" example:"2022-11-20"`
+	PostStatsTotals
+}
+
+type PostStatsResponse struct {
+	PostID int               `json:"post_id"`
+	From   string            `json:"from"`
+	To     string            `json:"to"`
+	Totals PostStatsTotals   `json:"totals"`
+	Days   []*PostDailyStats `json:"days"`
+}
+
+type PostStatsSummary struct {
+	PostID int             `json:"post_id"`
+	Title  string          `json:"title"`
+	Stats  PostStatsTotals `json:"stats"`
+}
+
+type AuthorStatsResponse struct {
+	From       string              `json:"from"`
+	To         string              `json:"to"`
+	PostsCount int                 `json:"posts_count"`
+	Totals     PostStatsTotals     `json:"totals"`
+	TopPosts   []*PostStatsSummary `json:"top_posts"`
+}
@@ -3,33 +3,117 @@ package models
 import "time"
 
 type Post struct {
-	Id          int         `json:"id" db:"id"`
-	Title       string      `json:"title" db:"title"`
-	Description string      `json:"description" db:"description"`
-	ImageUrl    string      `json:"image_url" db:"image_url"`
-	UserId      int         `json:"user_id" db:"user_id"`
-	CategoryId  int         `json:"category_id" db:"category_id"`
-	UpdatedAt   time.Time   `json:"updated_at" db:"updated_at"`
-	ViewsCount  int         `json:"views_count" db:"views_count"`
-	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
-	User        UserProfile `json:"user"`
+	Id              int            `json:"id" db:"id"`
+	Title           string         `json:"title" db:"title"`
+	Slug            string         `json:"slug" db:"slug"`
+	Description     string         `json:"description" db:"description"`
+	ContentFormat   string         `json:"content_format" db:"content_format"`
+	DescriptionHtml string         `json:"description_html,omitempty" db:"description_html"`
+	ReadingTime     int            `json:"reading_time_minutes" db:"reading_time_minutes"`
+	Excerpt         string         `json:"excerpt" db:"excerpt"`
+	TableOfContents []*PostHeading `json:"table_of_contents"`
+	ImageUrl        string         `json:"image_url" db:"image_url"`
+	UserId          int            `json:"user_id" db:"user_id"`
+	CategoryId      int            `json:"category_id" db:"category_id"`
+	UpdatedAt       time.Time      `json:"updated_at" db:"updated_at"`
+	ViewsCount      int            `json:"views_count" db:"views_count"`
+	CreatedAt       time.Time      `json:"created_at" db:"created_at"`
+	Status          string         `json:"status" db:"status"`
+	PublishedAt     *time.Time     `json:"published_at" db:"published_at"`
+	PublishAt       *time.Time     `json:"publish_at" db:"publish_at"`
+	User            UserProfile    `json:"user"`
+	Tags            []string       `json:"tags"`
+	Bookmarked      bool           `json:"bookmarked"`
+	ClapsCount      int            `json:"claps_count"`
+	MyClaps         int            `json:"my_claps,omitempty"`
+	LikesCount      int64          `json:"likes_count"`
+	DislikesCount   int64          `json:"dislikes_count"`
+	CommentsCount   int            `json:"comments_count"`
+	BookmarksCount  int            `json:"bookmarks_count"`
+	MyReaction      string         `json:"my_reaction,omitempty" enums:"like,dislike"`
+	Highlight       *PostHighlight `json:"highlight,omitempty"`
+}
+
+// PostSummary is a post in listings, without its body
+type PostSummary struct {
+	Id              int            `json:"id" db:"id"`
+	Title           string         `json:"title" db:"title"`
+	Slug            string         `json:"slug" db:"slug"`
+	ContentFormat   string         `json:"content_format" db:"content_format"`
+	ReadingTime     int            `json:"reading_time_minutes" db:"reading_time_minutes"`
+	Excerpt         string         `json:"excerpt" db:"excerpt"`
+	TableOfContents []*PostHeading `json:"table_of_contents"`
+	ImageUrl        string         `json:"image_url" db:"image_url"`
+	UserId          int            `json:"user_id" db:"user_id"`
+	CategoryId      int            `json:"category_id" db:"category_id"`
+	UpdatedAt       time.Time      `json:"updated_at" db:"updated_at"`
+	ViewsCount      int            `json:"views_count" db:"views_count"`
+	CreatedAt       time.Time      `json:"created_at" db:"created_at"`
+	Status          string         `json:"status" db:"status"`
+	PublishedAt     *time.Time     `json:"published_at" db:"published_at"`
+	PublishAt       *time.Time     `json:"publish_at" db:"publish_at"`
+	User            UserProfile    `json:"user"`
+	Tags            []string       `json:"tags"`
+	Bookmarked      bool           `json:"bookmarked"`
+	ClapsCount      int            `json:"claps_count"`
+	MyClaps         int            `json:"my_claps,omitempty"`
+	LikesCount      int64          `json:"likes_count"`
+	DislikesCount   int64          `json:"dislikes_count"`
+	CommentsCount   int            `json:"comments_count"`
+	BookmarksCount  int            `json:"bookmarks_count"`
+	MyReaction      string         `json:"my_reaction,omitempty" enums:"like,dislike"`
+	Highlight       *PostHighlight `json:"highlight,omitempty"`
+}
+
+type PostHighlight struct {
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	Rank        float64 `json:"rank"`
+}
+
+type PostHeading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	Id    string `json:"id"`
 }
 
 type CreatePost struct {
-	Title       string `json:"title" db:"title"`
-	Description string `json:"description" db:"description"`
-	ImageUrl    string `json:"image_url" db:"image_url"`
-	CategoryId  int    `json:"category_id" db:"category_id"`
+	Title         string     `json:"title" db:"title"`
+	Description   string     `json:"description" db:"description"`
+	ContentFormat string     `json:"content_format" binding:"omitempty,oneof=plain markdown" default:"plain"`
+	ImageUrl      string     `json:"image_url" db:"image_url"`
+	CategoryId    int        `json:"category_id" db:"category_id"`
+	Tags          []string   `json:"tags" binding:"max=10,dive,max=64"`
+	Status        string     `json:"status" binding:"omitempty,oneof=draft published unlisted" default:"draft"`
+	PublishAt     *time.Time `json:"publish_at"`
+}
+
+type UpdatePost struct {
+	Title         string     `json:"title" db:"title"`
+	Description   string     `json:"description" db:"description"`
+	ContentFormat string     `json:"content_format" binding:"omitempty,oneof=plain markdown" default:"plain"`
+	ImageUrl      string     `json:"image_url" db:"image_url"`
+	CategoryId    int        `json:"category_id" db:"category_id"`
+	Tags          []string   `json:"tags" binding:"max=10,dive,max=64"`
+	Status        string     `json:"status" binding:"omitempty,oneof=draft published unlisted archived"`
+	PublishAt     *time.Time `json:"publish_at"`
 }
 
 type GetAllPostsParams struct {
-	Limit      int `json:"limit" binding:"required" default:"10"`
-	Page       int `json:"page" binding:"required" default:"1"`
-	UserID     int `json:"user_id"`
-	CategoryId int `json:"category_id"`
+	Limit      int    `json:"limit" binding:"required" default:"10"`
+	Page       int    `json:"page" binding:"required" default:"1"`
+	UserID     int    `json:"user_id"`
+	CategoryId int    `json:"category_id"`
+	Search     string `json:"search"`
+	Tag        string `json:"tag"`
+	Status     string `json:"status" enums:"draft,scheduled,published,unlisted,archived"`
+	Sort       string `json:"sort" enums:"trending,top_week,top_month,most_viewed"`
+	Cursor     string `json:"cursor"`
+	WithCount  bool   `json:"with_count"`
 }
 
 type GetAllPostsResponse struct {
-	Posts []*Post `json:"posts"`
-	Count int     `json:"count"`
+	Posts      []*PostSummary `json:"posts"`
+	Count      int            `json:"count"`
+	NextCursor string         `json:"next_cursor,omitempty"`
 }
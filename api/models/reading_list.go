@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+type ReadingList struct {
+	Id          int        `json:"id"`
+	UserId      int        `json:"user_id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Visibility  string     `json:"visibility"`
+	IsDefault   bool       `json:"is_default"`
+	PostsCount  int        `json:"posts_count"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+}
+
+type CreateReadingList struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=500"`
+	Visibility  string `json:"visibility" binding:"omitempty,oneof=public private" default:"private"`
+}
+
+type GetAllReadingListsParams struct {
+	Limit int `json:"limit" binding:"required" default:"10"`
+	Page  int `json:"page" binding:"required" default:"1"`
+}
+
+type GetAllReadingListsResponse struct {
+	ReadingLists []*ReadingList `json:"reading_lists"`
+	Count        int            `json:"count"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+type Tag struct {
+	Id         int       `json:"id"`
+	Name       string    `json:"name"`
+	Slug       string    `json:"slug"`
+	PostsCount int       `json:"posts_count"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type GetAllTagsParams struct {
+	Limit  int    `json:"limit" binding:"required" default:"10"`
+	Page   int    `json:"page" binding:"required" default:"1"`
+	Search string `json:"search"`
+}
+
+type GetAllTagsResponse struct {
+	Tags  []*Tag `json:"tags"`
+	Count int    `json:"count"`
+}
@@ -35,9 +35,12 @@ type GetAllUsersParams struct {
 	Page       int    `json:"page" binding:"required" default:"1"`
 	Search     string `json:"search"`
 	SortByDate string `json:"sort_by_date" enums:"asc,desc"`
+	Cursor     string `json:"cursor"`
+	WithCount  bool   `json:"with_count"`
 }
 
 type GetAllUsersResponse struct {
-	Users []*User `json:"users"`
-	Count int     `json:"count"`
+	Users      []*User `json:"users"`
+	Count      int     `json:"count"`
+	NextCursor string  `json:"next_cursor,omitempty"`
 }
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -11,6 +12,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/post/api/models"
 	"github.com/post/pkg/utils"
+	"github.com/post/storage"
 	"github.com/post/storage/repo"
 )
 
@@ -49,7 +51,7 @@ func (h *handlerV1) Register(c *gin.Context) {
 		return
 	}
 
-	_, err = h.storage.User().CheckInfo(req.Email, req.Username)
+	_, err = h.storage.User().CheckInfo(c.Request.Context(), req.Email, req.Username)
 	if !errors.Is(err, sql.ErrNoRows) {
 		c.JSON(http.StatusBadRequest, errorResponse(ErrEmailExists))
 		return
@@ -80,7 +82,7 @@ func (h *handlerV1) Register(c *gin.Context) {
 		return
 	}
 
-	err = h.inMemory.SetWithTTL("user_"+user.Email, string(userData), 10)
+	err = h.inMemory.SetWithTTL(c.Request.Context(), "user_"+user.Email, string(userData), 10)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: err.Error(),
@@ -89,7 +91,8 @@ func (h *handlerV1) Register(c *gin.Context) {
 	}
 
 	go func() {
-		err := h.sendVerificationCode(RegisterCodeKey, req.Email)
+		// the request is over by the time the code is sent
+		err := h.sendVerificationCode(context.Background(), RegisterCodeKey, req.Email)
 		if err != nil {
 			fmt.Printf("failed to send verification code: %v", err)
 		}
@@ -122,7 +125,7 @@ func (h *handlerV1) Verify(c *gin.Context) {
 		return
 	}
 
-	userData, err := h.inMemory.Get("user_" + req.Email)
+	userData, err := h.inMemory.Get(c.Request.Context(), "user_"+req.Email)
 	if err != nil {
 		c.JSON(http.StatusForbidden, models.ErrorResponse{
 			Error: err.Error(),
@@ -139,7 +142,7 @@ func (h *handlerV1) Verify(c *gin.Context) {
 		return
 	}
 
-	code, err := h.inMemory.Get(RegisterCodeKey + user.Email)
+	code, err := h.inMemory.Get(c.Request.Context(), RegisterCodeKey+user.Email)
 	if err != nil {
 		c.JSON(http.StatusForbidden, errorResponse(ErrCodeExpired))
 		return
@@ -150,7 +153,18 @@ func (h *handlerV1) Verify(c *gin.Context) {
 		return
 	}
 
-	result, err := h.storage.User().Create(&user)
+	// the user comes with their default reading list, or not at all
+	var result *repo.User
+	err = h.storage.WithTx(c.Request.Context(), func(tx storage.StorageI) error {
+		var err error
+		result, err = tx.User().Create(c.Request.Context(), &user)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ReadingList().GetOrCreateDefault(c.Request.Context(), result.Id)
+		return err
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: err.Error(),
@@ -208,7 +222,7 @@ func (h *handlerV1) Login(c *gin.Context) {
 		return
 	}
 
-	result, err := h.storage.User().GetByEmail(req.Email)
+	result, err := h.storage.User().GetByEmail(c.Request.Context(), req.Email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusForbidden, errorResponse(ErrWrongEmailOrPass))
@@ -277,7 +291,7 @@ func (h *handlerV1) ForgotPassword(c *gin.Context) {
 		return
 	}
 
-	_, err = h.storage.User().GetByEmail(req.Email)
+	_, err = h.storage.User().GetByEmail(c.Request.Context(), req.Email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusNotFound, models.ErrorResponse{
@@ -293,7 +307,8 @@ func (h *handlerV1) ForgotPassword(c *gin.Context) {
 	}
 
 	go func() {
-		err := h.sendVerificationCode(ForgotPasswordKey, req.Email)
+		// the request is over by the time the code is sent
+		err := h.sendVerificationCode(context.Background(), ForgotPasswordKey, req.Email)
 		if err != nil {
 			fmt.Printf("failed to send verification code: %v", err)
 		}
@@ -326,7 +341,7 @@ func (h *handlerV1) VerifyForgotPassword(c *gin.Context) {
 		return
 	}
 
-	code, err := h.inMemory.Get(ForgotPasswordKey + req.Email)
+	code, err := h.inMemory.Get(c.Request.Context(), ForgotPasswordKey+req.Email)
 	if err != nil {
 		c.JSON(http.StatusForbidden, errorResponse(ErrCodeExpired))
 		return
@@ -337,7 +352,7 @@ func (h *handlerV1) VerifyForgotPassword(c *gin.Context) {
 		return
 	}
 
-	result, err := h.storage.User().GetByEmail(req.Email)
+	result, err := h.storage.User().GetByEmail(c.Request.Context(), req.Email)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error: err.Error(),
@@ -412,7 +427,7 @@ func (h *handlerV1) UpdatePassword(c *gin.Context) {
 		return
 	}
 
-	err = h.storage.User().UpdatePassword(&repo.UpdatePassword{
+	err = h.storage.User().UpdatePassword(c.Request.Context(), &repo.UpdatePassword{
 		UserID:   int64(payload.UserId),
 		Password: hashedPassword,
 	})
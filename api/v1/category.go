@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/post/api/models"
@@ -29,7 +30,7 @@ func (h *handlerV1) GetCategory(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.storage.Category().Get(id)
+	resp, err := h.storage.Category().Get(c.Request.Context(), id)
 	if err != nil {
 		fmt.Println("Error at GetCategory 2")
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -80,7 +81,7 @@ func (h *handlerV1) CreateCategory(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.storage.Category().Create(&repo.Category{
+	resp, err := h.storage.Category().Create(c.Request.Context(), &repo.Category{
 		Title: req.Title,
 	})
 	if err != nil {
@@ -100,6 +101,8 @@ func (h *handlerV1) CreateCategory(c *gin.Context) {
 // @Router /categories [get]
 // @Summary Get all categories
 // @Description Get all categories
+// @Description Pass an empty cursor to page by cursor, then next_cursor of the response as cursor.
+// @Description The count is only computed in page mode, unless with_count is set
 // @Tags category
 // @Accept json
 // @Produce json
@@ -112,10 +115,19 @@ func (h *handlerV1) GetAllCategories(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
-	result, err := h.storage.Category().GetAll(repo.GetCategoryQuery{
-		Page:   req.Page,
-		Limit:  req.Limit,
-		Search: req.Search,
+	cursor, err := cursorParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	page, limit := cursor.page(req.Page, req.Limit)
+	result, err := h.storage.Category().GetAll(c.Request.Context(), repo.GetCategoryQuery{
+		Page:      page,
+		Limit:     limit,
+		Search:    req.Search,
+		After:     cursor.after,
+		SkipCount: !cursor.withCount,
 	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -124,28 +136,31 @@ func (h *handlerV1) GetAllCategories(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, categoryResponse(result))
+	var next string
+	result.Categories, next = nextPage(cursor, result.Categories, req.Limit, func(category *repo.Category) (time.Time, int) {
+		return category.CreatedAt, category.Id
+	})
+
+	response := categoryResponse(result)
+	response.NextCursor = next
+	c.JSON(http.StatusOK, response)
 }
 
 func categoryParams(c *gin.Context) (*models.GetAllCategoryParams, error) {
 	var (
-		limit int = 10
-		page  int = 1
+		limit int
+		page  int
 		err   error
 	)
 
-	if c.Query("limit") != "" {
-		limit, err = strconv.Atoi(c.Query("limit"))
-		if err != nil {
-			return nil, err
-		}
+	limit, err = limitParam(c, maxListLimit)
+	if err != nil {
+		return nil, err
 	}
 
-	if c.Query("page") != "" {
-		page, err = strconv.Atoi(c.Query("page"))
-		if err != nil {
-			return nil, err
-		}
+	page, err = pageParam(c)
+	if err != nil {
+		return nil, err
 	}
 
 	return &models.GetAllCategoryParams{
@@ -218,7 +233,7 @@ func (h *handlerV1) UpdateCategory(ctx *gin.Context) {
 	}
 
 	b.Id = id
-	category, err := h.storage.Category().Update(&repo.Category{
+	category, err := h.storage.Category().Update(ctx.Request.Context(), &repo.Category{
 		Id:    b.Id,
 		Title: b.Title,
 	})
@@ -262,7 +277,7 @@ func (h *handlerV1) DeleteCategory(ctx *gin.Context) {
 		return
 	}
 
-	err = h.storage.Category().Delete(id)
+	err = h.storage.Category().Delete(ctx.Request.Context(), id)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"message": "failed to Delete method",
@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/post/api/models"
+	"github.com/post/storage/repo"
+)
+
+// @Security ApiKeyAuth
+// @Router /posts/{id}/claps [post]
+// @Summary Clap for a post
+// @Description Adds count claps of the caller, up to 50 per post in total.
+// @Description Claps are saved in the background, so claps_count of the posts catches up a few seconds later
+// @Tags claps
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param clap body models.ClapRequest true "clap"
+// @Success 200 {object} models.ClapResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) ClapPost(c *gin.Context) {
+	var req models.ClapRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	payload, err := h.GetAuthPayload(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	post, ok := h.visiblePost(c)
+	if !ok {
+		return
+	}
+	if post.Status != repo.PostStatusPublished && post.Status != repo.PostStatusUnlisted {
+		c.JSON(http.StatusBadRequest, errorResponse(ErrInvalidPostStatus))
+		return
+	}
+
+	myClaps, err := h.claps.Add(c.Request.Context(), post.Id, payload.UserId, req.Count)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	stored, err := h.storage.Clap().Get(c.Request.Context(), post.Id, payload.UserId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	total, err := h.storage.Clap().GetTotal(c.Request.Context(), post.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ClapResponse{
+		PostID:     post.Id,
+		MyClaps:    myClaps,
+		ClapsCount: total - stored + myClaps,
+	})
+}
+
+// @Router /posts/{id}/clappers [get]
+// @Summary Get clappers
+// @Description Get users who clapped for the post, most claps first
+// @Tags claps
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param filter query models.GetAllClappersParams false "Filter"
+// @Success 200 {object} models.GetAllClappersResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) GetClappers(c *gin.Context) {
+	limit, err := limitParam(c, maxListLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	page, err := pageParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	post, ok := h.visiblePost(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.storage.Clap().GetClappers(c.Request.Context(), repo.GetClappersQuery{
+		PostID: post.Id,
+		Page:   page,
+		Limit:  limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := models.GetAllClappersResponse{
+		Clappers: make([]*models.Clapper, 0),
+		Count:    result.Count,
+	}
+	for _, clapper := range result.Clappers {
+		response.Clappers = append(response.Clappers, &models.Clapper{
+			User:  models.UserProfile(clapper.User),
+			Claps: clapper.Count,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -1,14 +1,24 @@
 package v1
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/post/api/models"
+	"github.com/post/pkg/utils"
 	"github.com/post/storage/repo"
 )
 
+const (
+	maxCommentDepth    = 10
+	deletedCommentText = "[deleted]"
+)
+
 // @Router /comments/{id} [get]
 // @Summary Get comment by id
 // @Description Get comment by id
@@ -27,7 +37,7 @@ func (h *handlerV1) GetComment(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.storage.Comment().Get(id)
+	resp, err := h.storage.Comment().Get(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: err.Error(),
@@ -35,21 +45,14 @@ func (h *handlerV1) GetComment(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, models.Comment{
-		Id:          resp.Id,
-		PostId:      resp.PostId,
-		UserId:      resp.UserId,
-		Description: resp.Description,
-		CreatedAt:   resp.CreatedAt,
-		UpdatedAt:   resp.UpdatedAt,
-		User: &models.UserProfile{
-			Id:              resp.UserId,
-			FirstName:       resp.User.FirstName,
-			LastName:        resp.User.LastName,
-			Email:           resp.User.Email,
-			ProfileImageUrl: resp.User.ProfileImageUrl,
-		},
-	})
+	payload, _ := h.GetAuthPayload(c)
+	comment := parseCommentModel(resp)
+	if err := h.setCommentReactions(c.Request.Context(), []*models.Comment{&comment}, viewerID(payload)); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
 }
 
 // @Security ApiKeyAuth
@@ -84,10 +87,30 @@ func (h *handlerV1) CreateComment(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.storage.Comment().Create(&repo.Comment{
+	if req.ParentId != nil {
+		parent, err := h.storage.Comment().Get(c.Request.Context(), *req.ParentId)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				c.JSON(http.StatusNotFound, errorResponse(ErrNotFound))
+				return
+			}
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if req.PostId == 0 {
+			req.PostId = parent.PostId
+		}
+		if parent.Deleted || parent.PostId != req.PostId {
+			c.JSON(http.StatusBadRequest, errorResponse(ErrInvalidParentComment))
+			return
+		}
+	}
+
+	resp, err := h.storage.Comment().Create(c.Request.Context(), &repo.Comment{
 		PostId:      req.PostId,
 		UserId:      usr.UserId,
 		Description: req.Description,
+		ParentId:    req.ParentId,
 	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -103,6 +126,7 @@ func (h *handlerV1) CreateComment(c *gin.Context) {
 		Description: resp.Description,
 		CreatedAt:   resp.CreatedAt,
 		UpdatedAt:   resp.UpdatedAt,
+		ParentId:    resp.ParentId,
 		User: &models.UserProfile{
 			Id:              usr.UserId,
 			FirstName:       usr.FirstName,
@@ -115,12 +139,15 @@ func (h *handlerV1) CreateComment(c *gin.Context) {
 
 // @Router /comments [get]
 // @Summary Get all comments
-// @Description Get all comments
+// @Description Get all comments, sort=top orders them by reactions. With depth > 0 only top level comments are
+// @Description listed and their replies are nested down to depth levels
+// @Description Pass an empty cursor to page by cursor, then next_cursor of the response as cursor.
+// @Description The count is only computed in page mode, unless with_count is set
 // @Tags comments
 // @Accept json
 // @Produce json
 // @Param filter query models.GetAllCommentsParams false "Filter"
-// @Success 200 {object} models.GetAllResponse
+// @Success 200 {object} models.GetAllCommentsResponse
 // @Failure 500 {object} models.ErrorResponse
 func (h *handlerV1) GetAllComment(c *gin.Context) {
 	req, err := commentsParams(c)
@@ -131,44 +158,245 @@ func (h *handlerV1) GetAllComment(c *gin.Context) {
 		return
 	}
 
-	result, err := h.storage.Comment().GetAll(repo.GetCommentQuery{
+	h.comments(c, req, repo.GetCommentQuery{
 		Page:       req.Page,
 		Limit:      req.Limit,
 		PostId:     req.PostID,
 		SortByDate: req.SortByDate,
 		UserId:     req.UserID,
+		RootsOnly:  req.Depth > 0,
+		Sort:       req.Sort,
 	})
+}
+
+// @Router /comments/{id}/replies [get]
+// @Summary Get comment replies
+// @Description Get direct replies of a comment, optionally with their own replies nested down to depth levels
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param filter query models.GetAllCommentsParams false "Filter"
+// @Success 200 {object} models.GetAllCommentsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) GetCommentReplies(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error: err.Error(),
-		})
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	req, err := commentsParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	h.comments(c, req, repo.GetCommentQuery{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		SortByDate: req.SortByDate,
+		ParentId:   id,
+		Sort:       req.Sort,
+	})
+}
+
+func (h *handlerV1) comments(c *gin.Context, req *models.GetAllCommentsParams, query repo.GetCommentQuery) {
+	cursor, err := cursorParams(c)
+	if err == nil && cursor.enabled && query.Sort == repo.CommentSortTop {
+		err = ErrCursorOrder
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	query.Page, query.Limit = cursor.page(query.Page, query.Limit)
+	query.After, query.SkipCount = cursor.after, !cursor.withCount
+
+	result, err := h.storage.Comment().GetAll(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var next string
+	result.Comments, next = nextPage(cursor, result.Comments, req.Limit, func(comment *repo.Comment) (time.Time, int) {
+		return comment.CreatedAt, comment.Id
+	})
+
+	response := commentsResponse(result)
+	response.NextCursor = next
+	if req.Depth > 0 {
+		ids := make([]int, 0, len(result.Comments))
+		for _, comment := range result.Comments {
+			ids = append(ids, comment.Id)
+		}
+
+		replies, err := h.storage.Comment().GetDescendants(c.Request.Context(), ids, req.Depth)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		nestComments(response.Comments, replies)
+	}
+
+	payload, _ := h.GetAuthPayload(c)
+	if err := h.setCommentReactions(c.Request.Context(), response.Comments, viewerID(payload)); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// setCommentReactions fills the reaction counts of the comments and
+// their nested replies with a single query
+func (h *handlerV1) setCommentReactions(ctx context.Context, comments []*models.Comment, viewerID int) error {
+	byID := make(map[int]*models.Comment)
+	var walk func([]*models.Comment)
+	walk = func(list []*models.Comment) {
+		for _, comment := range list {
+			byID[comment.Id] = comment
+			comment.Reactions = make(map[string]int)
+			walk(comment.Replies)
+		}
+	}
+	walk(comments)
+
+	ids := make([]int, 0, len(byID))
+	for id := range byID {
+		ids = append(ids, id)
+	}
+
+	reactions, err := h.storage.CommentReaction().GetReactions(ctx, ids, viewerID)
+	if err != nil {
+		return err
+	}
+
+	for id, r := range reactions {
+		byID[id].Reactions = r.Counts
+		byID[id].MyReaction = r.MyReaction
+	}
+
+	return nil
+}
+
+// @Security ApiKeyAuth
+// @Router /comments/{id}/reactions [post]
+// @Summary React to a comment
+// @Description Sending the current reaction again removes it, another reaction replaces it
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param reaction body models.CommentReactionRequest true "reaction"
+// @Success 200 {object} models.CommentReactionsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) ReactToComment(c *gin.Context) {
+	var req models.CommentReactionRequest
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	payload, err := h.GetAuthPayload(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	comment, err := h.storage.Comment().Get(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(ErrNotFound))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if comment.Deleted {
+		c.JSON(http.StatusBadRequest, errorResponse(ErrCommentDeleted))
+		return
+	}
+
+	err = h.storage.CommentReaction().CreateOrUpdate(c.Request.Context(), &repo.CommentReaction{
+		CommentID: id,
+		UserID:    payload.UserId,
+		Reaction:  req.Reaction,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	reactions, err := h.storage.CommentReaction().GetReactions(c.Request.Context(), []int{id}, payload.UserId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, commentsResponse(h, result))
+	response := models.CommentReactionsResponse{
+		Reactions: make(map[string]int),
+	}
+	if r, ok := reactions[id]; ok {
+		response.Reactions = r.Counts
+		response.MyReaction = r.MyReaction
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// nestComments attaches the replies to their parents. Replies must be
+// ordered so that every parent comes before its own replies
+func nestComments(roots []*models.Comment, replies []*repo.Comment) {
+	byID := make(map[int]*models.Comment, len(roots)+len(replies))
+	for _, root := range roots {
+		byID[root.Id] = root
+	}
+
+	for _, reply := range replies {
+		if reply.ParentId == nil {
+			continue
+		}
+		parent, ok := byID[*reply.ParentId]
+		if !ok {
+			continue
+		}
+		r := parseCommentModel(reply)
+		parent.Replies = append(parent.Replies, &r)
+		byID[r.Id] = &r
+	}
 }
 
 func commentsParams(c *gin.Context) (*models.GetAllCommentsParams, error) {
 	var (
-		limit          int = 10
-		page           int = 1
+		limit          int
+		page           int
 		err            error
 		sortByDate     string
 		PostId, UserId int
+		depth          int
+		sort           string
 	)
 
-	if c.Query("limit") != "" {
-		limit, err = strconv.Atoi(c.Query("limit"))
-		if err != nil {
-			return nil, err
-		}
+	limit, err = limitParam(c, maxListLimit)
+	if err != nil {
+		return nil, err
 	}
 
-	if c.Query("page") != "" {
-		page, err = strconv.Atoi(c.Query("page"))
-		if err != nil {
-			return nil, err
-		}
+	page, err = pageParam(c)
+	if err != nil {
+		return nil, err
 	}
 
 	if c.Query("sort_by_date") != "" &&
@@ -190,31 +418,38 @@ func commentsParams(c *gin.Context) (*models.GetAllCommentsParams, error) {
 		}
 	}
 
+	if c.Query("sort") == repo.CommentSortTop {
+		sort = repo.CommentSortTop
+	}
+
+	if c.Query("depth") != "" {
+		depth, err = strconv.Atoi(c.Query("depth"))
+		if err != nil {
+			return nil, err
+		}
+		if depth < 0 || depth > maxCommentDepth {
+			depth = maxCommentDepth
+		}
+	}
+
 	return &models.GetAllCommentsParams{
 		Limit:      limit,
 		Page:       page,
 		SortByDate: sortByDate,
 		PostID:     PostId,
 		UserID:     UserId,
+		Depth:      depth,
+		Sort:       sort,
 	}, nil
 }
 
-func commentsResponse(h *handlerV1, data *repo.GetAllCommentsResult) *models.GetAllCommentsResponse {
+func commentsResponse(data *repo.GetAllCommentsResult) *models.GetAllCommentsResponse {
 	response := models.GetAllCommentsResponse{
 		Comments: make([]*models.Comment, 0),
 		Count:    data.Count,
 	}
 
 	for _, comment := range data.Comments {
-		usr, _ := h.storage.User().GetUserProfileInfo(comment.UserId)
-		comment.User = repo.UserProfile{
-			Id:              comment.UserId,
-			FirstName:       usr.FirstName,
-			LastName:        usr.LastName,
-			Email:           usr.Email,
-			ProfileImageUrl: usr.ProfileImageUrl,
-		}
-
 		p := parseCommentModel(comment)
 		response.Comments = append(response.Comments, &p)
 	}
@@ -223,6 +458,19 @@ func commentsResponse(h *handlerV1, data *repo.GetAllCommentsResult) *models.Get
 }
 
 func parseCommentModel(Comment *repo.Comment) models.Comment {
+	if Comment.Deleted {
+		return models.Comment{
+			Id:          Comment.Id,
+			PostId:      Comment.PostId,
+			Description: deletedCommentText,
+			CreatedAt:   Comment.CreatedAt,
+			UpdatedAt:   Comment.UpdatedAt,
+			ParentId:    Comment.ParentId,
+			ReplyCount:  Comment.ReplyCount,
+			Deleted:     true,
+		}
+	}
+
 	return models.Comment{
 		Id:          Comment.Id,
 		PostId:      Comment.PostId,
@@ -230,6 +478,8 @@ func parseCommentModel(Comment *repo.Comment) models.Comment {
 		Description: Comment.Description,
 		CreatedAt:   Comment.CreatedAt,
 		UpdatedAt:   Comment.UpdatedAt,
+		ParentId:    Comment.ParentId,
+		ReplyCount:  Comment.ReplyCount,
 		User: &models.UserProfile{
 			Id:              Comment.UserId,
 			FirstName:       Comment.User.FirstName,
@@ -240,6 +490,11 @@ func parseCommentModel(Comment *repo.Comment) models.Comment {
 	}
 }
 
+// canManageComment reports whether the caller wrote the comment or is a superadmin
+func canManageComment(payload *utils.Payload, ownerID int) bool {
+	return canManagePost(payload, ownerID)
+}
+
 // @Security ApiKeyAuth
 // @Summary Update a comment
 // @Description Update a comments
@@ -275,14 +530,14 @@ func (h *handlerV1) UpdateComment(ctx *gin.Context) {
 		return
 	}
 
-	if payload.UserType != repo.UserTypeSuperadmin || payload.UserId!= h.storage.Comment().GetUserInfo(id) {
+	if !canManageComment(payload, h.storage.Comment().GetUserInfo(ctx.Request.Context(), id)) {
 		ctx.JSON(http.StatusForbidden, errorResponse(ErrForbidden))
 		return
 	}
 
 
 	b.Id = id
-	comment, err := h.storage.Comment().Update(&repo.Comment{
+	comment, err := h.storage.Comment().Update(ctx.Request.Context(), &repo.Comment{
 		Id:          b.Id,
 		Description: b.Description,
 	})
@@ -292,19 +547,25 @@ func (h *handlerV1) UpdateComment(ctx *gin.Context) {
 		})
 		return
 	}
-	profil, _ := h.storage.User().GetUserProfileInfo(comment.UserId)
-	comment.User.Id = profil.Id
-	comment.User.FirstName = profil.FirstName
-	comment.User.LastName = profil.LastName
-	comment.User.Email = profil.Email
-	comment.User.ProfileImageUrl = profil.ProfileImageUrl
+	profil, err := h.storage.User().GetUserProfileInfo(ctx.Request.Context(), comment.UserId)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if profil != nil {
+		comment.User.Id = profil.Id
+		comment.User.FirstName = profil.FirstName
+		comment.User.LastName = profil.LastName
+		comment.User.Email = profil.Email
+		comment.User.ProfileImageUrl = profil.ProfileImageUrl
+	}
 
 	ctx.JSON(http.StatusOK, comment)
 }
 
 // @Security ApiKeyAuth
 // @Summary Delete a comment
-// @Description Delete a comment
+// @Description Delete a comment. A comment with replies is replaced with "[deleted]" and its replies are kept
 // @Tags comments
 // @Accept json
 // @Produce json
@@ -325,12 +586,16 @@ func (h *handlerV1) DeleteComment(ctx *gin.Context) {
 		return
 	}
 
-	if payload.UserType != repo.UserTypeSuperadmin || payload.UserId!= h.storage.Comment().GetUserInfo(id) {
+	if !canManageComment(payload, h.storage.Comment().GetUserInfo(ctx.Request.Context(), id)) {
 		ctx.JSON(http.StatusForbidden, errorResponse(ErrForbidden))
 		return
 	}
 
-	err = h.storage.Comment().Delete(id)
+	err = h.storage.Comment().Delete(ctx.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, errorResponse(ErrNotFound))
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"message": "failed to Delete method",
@@ -0,0 +1,107 @@
+package v1
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/post/pkg/utils"
+	"github.com/post/storage/repo"
+)
+
+const maxListLimit = 100
+
+// listCursor is the cursor pagination of a list request. A list is paged by
+// cursor once the cursor parameter is given, empty for the first page, and
+// by page and limit otherwise. Only page mode counts the rows by default
+type listCursor struct {
+	enabled   bool
+	after     *repo.Cursor
+	withCount bool
+}
+
+func cursorParams(c *gin.Context) (*listCursor, error) {
+	cursor, enabled := c.GetQuery("cursor")
+	result := listCursor{
+		enabled:   enabled,
+		withCount: !enabled,
+	}
+
+	if cursor != "" {
+		createdAt, id, err := utils.DecodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		result.after = &repo.Cursor{
+			CreatedAt: createdAt,
+			Id:        id,
+		}
+	}
+
+	if c.Query("with_count") != "" {
+		withCount, err := strconv.ParseBool(c.Query("with_count"))
+		if err != nil {
+			return nil, err
+		}
+		result.withCount = withCount
+	}
+
+	return &result, nil
+}
+
+// limitParam parses the limit of a list request, 10 by default. A limit
+// out of 1..max falls back to max
+func limitParam(c *gin.Context, max int) (int, error) {
+	limit := 10
+	if c.Query("limit") != "" {
+		var err error
+		limit, err = strconv.Atoi(c.Query("limit"))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if limit <= 0 || limit > max {
+		limit = max
+	}
+	return limit, nil
+}
+
+// pageParam parses the page of a list request, 1 by default. Pages start
+// at 1, smaller ones are refused with ErrInvalidPage
+func pageParam(c *gin.Context) (int, error) {
+	if c.Query("page") == "" {
+		return 1, nil
+	}
+
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil {
+		return 0, err
+	}
+	if page < 1 {
+		return 0, ErrInvalidPage
+	}
+	return page, nil
+}
+
+// page returns the page and limit to query. In cursor mode the page is
+// always the first one after the cursor, with one extra row telling
+// whether there is a next page
+func (lc *listCursor) page(page, limit int) (int, int) {
+	if lc.enabled {
+		return 1, limit + 1
+	}
+	return page, limit
+}
+
+// nextPage cuts the extra row fetched in cursor mode and returns the cursor
+// of the next page, empty on the last one and in page mode
+func nextPage[T any](lc *listCursor, items []T, limit int, key func(T) (time.Time, int)) ([]T, string) {
+	if !lc.enabled || limit <= 0 || len(items) <= limit {
+		return items, ""
+	}
+
+	items = items[:limit]
+	createdAt, id := key(items[len(items)-1])
+	return items, utils.EncodeCursor(createdAt, id)
+}
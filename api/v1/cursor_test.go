@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/post/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func cursorRequest(t *testing.T, query string) *listCursor {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts"+query, nil)
+
+	lc, err := cursorParams(c)
+	require.NoError(t, err)
+	return lc
+}
+
+func TestCursorParams(t *testing.T) {
+	lc := cursorRequest(t, "?page=3")
+	require.False(t, lc.enabled)
+	require.True(t, lc.withCount)
+	page, limit := lc.page(3, 10)
+	require.Equal(t, 3, page)
+	require.Equal(t, 10, limit)
+
+	lc = cursorRequest(t, "?cursor=")
+	require.True(t, lc.enabled)
+	require.False(t, lc.withCount)
+	require.Nil(t, lc.after)
+	page, limit = lc.page(3, 10)
+	require.Equal(t, 1, page)
+	require.Equal(t, 11, limit)
+
+	at := time.Date(2022, 11, 20, 10, 0, 0, 0, time.UTC)
+	lc = cursorRequest(t, "?with_count=true&cursor="+utils.EncodeCursor(at, 7))
+	require.True(t, lc.withCount)
+	require.Equal(t, 7, lc.after.Id)
+	require.True(t, at.Equal(lc.after.CreatedAt))
+}
+
+func TestNextPage(t *testing.T) {
+	key := func(id int) (time.Time, int) {
+		return time.Unix(int64(id), 0), id
+	}
+
+	lc := &listCursor{enabled: true}
+	items, next := nextPage(lc, []int{5, 4, 3}, 2, key)
+	require.Equal(t, []int{5, 4}, items)
+	require.Equal(t, utils.EncodeCursor(time.Unix(4, 0), 4), next)
+
+	items, next = nextPage(lc, []int{2, 1}, 2, key)
+	require.Equal(t, []int{2, 1}, items)
+	require.Empty(t, next)
+
+	items, next = nextPage(&listCursor{}, []int{5, 4, 3}, 2, key)
+	require.Equal(t, []int{5, 4, 3}, items)
+	require.Empty(t, next)
+}
+
+func TestNextPageNoLimit(t *testing.T) {
+	key := func(id int) (time.Time, int) {
+		return time.Unix(int64(id), 0), id
+	}
+
+	for _, limit := range []int{0, -1} {
+		items, next := nextPage(&listCursor{enabled: true}, []int{2, 1}, limit, key)
+		require.Equal(t, []int{2, 1}, items)
+		require.Empty(t, next)
+	}
+}
+
+func TestLimitParam(t *testing.T) {
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", 10},
+		{"?limit=5", 5},
+		{"?limit=0", maxListLimit},
+		{"?limit=-1", maxListLimit},
+		{"?limit=1000", maxListLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts"+tt.query, nil)
+
+			limit, err := limitParam(c, maxListLimit)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, limit)
+		})
+	}
+}
+
+func TestPageParam(t *testing.T) {
+	tests := []struct {
+		query string
+		want  int
+		err   bool
+	}{
+		{"", 1, false},
+		{"?page=3", 3, false},
+		{"?page=0", 0, true},
+		{"?page=-1", 0, true},
+		{"?page=x", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts"+tt.query, nil)
+
+			page, err := pageParam(c)
+			require.Equal(t, tt.err, err != nil)
+			require.Equal(t, tt.want, page)
+		})
+	}
+}
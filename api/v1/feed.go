@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/post/api/models"
+	"github.com/post/storage/repo"
+)
+
+const maxFeedLimit = 50
+
+// @Security ApiKeyAuth
+// @Router /feed [get]
+// @Summary Get home feed
+// @Description Posts of the followed authors and categories, newest first.
+// @Description Pass next_cursor of the response as cursor to get the next page
+// @Tags feed
+// @Accept json
+// @Produce json
+// @Param filter query models.GetFeedParams false "Filter"
+// @Success 200 {object} models.FeedResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) GetFeed(c *gin.Context) {
+	payload, err := h.GetAuthPayload(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	query, cursor, err := feedParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	query.UserID = payload.UserId
+
+	limit := query.Limit
+	_, query.Limit = cursor.page(1, limit)
+	posts, err := h.storage.Post().GetFeed(c.Request.Context(), *query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	posts, next := nextPage(cursor, posts, limit, func(p *repo.Post) (time.Time, int) {
+		return p.CreatedAt, p.Id
+	})
+
+	response := models.FeedResponse{
+		Posts:      postsResponse(&repo.GetAllPostResult{Post: posts}).Posts,
+		NextCursor: next,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func feedParams(c *gin.Context) (*repo.GetFeedQuery, *listCursor, error) {
+	limit, err := limitParam(c, maxFeedLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cursor, err := cursorParams(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the feed has no pages, only cursors
+	cursor.enabled = true
+
+	return &repo.GetFeedQuery{
+		Limit: limit,
+		After: cursor.after,
+	}, cursor, nil
+}
@@ -0,0 +1,276 @@
+package v1
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/post/api/models"
+	"github.com/post/pkg/utils"
+	"github.com/post/storage/repo"
+)
+
+// @Security ApiKeyAuth
+// @Router /users/{id}/follow [post]
+// @Summary Follow a user
+// @Description Follow an author to see their posts in the feed
+// @Tags follow
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.ResponseOK
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) FollowUser(c *gin.Context) {
+	payload, followeeID, ok := h.followTarget(c)
+	if !ok {
+		return
+	}
+
+	if payload.UserId == followeeID {
+		c.JSON(http.StatusBadRequest, errorResponse(ErrFollowSelf))
+		return
+	}
+
+	if _, err := h.storage.User().Get(c.Request.Context(), followeeID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(ErrNotFound))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err := h.storage.Follow().Follow(c.Request.Context(), payload.UserId, followeeID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseOK{
+		Message: "followed",
+	})
+}
+
+// @Security ApiKeyAuth
+// @Router /users/{id}/follow [delete]
+// @Summary Unfollow a user
+// @Description Unfollow a user
+// @Tags follow
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.ResponseOK
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) UnfollowUser(c *gin.Context) {
+	payload, followeeID, ok := h.followTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.storage.Follow().Unfollow(c.Request.Context(), payload.UserId, followeeID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(ErrNotFound))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseOK{
+		Message: "unfollowed",
+	})
+}
+
+// @Router /users/{id}/followers [get]
+// @Summary Get followers
+// @Description Get users following the user
+// @Tags follow
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param filter query models.GetAllFollowsParams false "Filter"
+// @Success 200 {object} models.GetAllFollowsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) GetFollowers(c *gin.Context) {
+	h.follows(c, h.storage.Follow().GetFollowers)
+}
+
+// @Router /users/{id}/following [get]
+// @Summary Get following
+// @Description Get users the user follows
+// @Tags follow
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param filter query models.GetAllFollowsParams false "Filter"
+// @Success 200 {object} models.GetAllFollowsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) GetFollowing(c *gin.Context) {
+	h.follows(c, h.storage.Follow().GetFollowing)
+}
+
+func (h *handlerV1) follows(c *gin.Context, get func(context.Context, repo.GetFollowQuery) (*repo.GetAllFollowsResult, error)) {
+	query, ok := followQuery(c)
+	if !ok {
+		return
+	}
+
+	result, err := get(c.Request.Context(), *query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := models.GetAllFollowsResponse{
+		Users: make([]*models.UserProfile, 0),
+		Count: result.Count,
+	}
+	for _, u := range result.Users {
+		p := models.UserProfile(*u)
+		response.Users = append(response.Users, &p)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Security ApiKeyAuth
+// @Router /categories/{id}/follow [post]
+// @Summary Follow a category
+// @Description Follow a category to see its posts in the feed
+// @Tags follow
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.ResponseOK
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) FollowCategory(c *gin.Context) {
+	payload, categoryID, ok := h.followTarget(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.storage.Category().Get(c.Request.Context(), categoryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(ErrNotFound))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err := h.storage.Follow().FollowCategory(c.Request.Context(), payload.UserId, categoryID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseOK{
+		Message: "followed",
+	})
+}
+
+// @Security ApiKeyAuth
+// @Router /categories/{id}/follow [delete]
+// @Summary Unfollow a category
+// @Description Unfollow a category
+// @Tags follow
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.ResponseOK
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) UnfollowCategory(c *gin.Context) {
+	payload, categoryID, ok := h.followTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.storage.Follow().UnfollowCategory(c.Request.Context(), payload.UserId, categoryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(ErrNotFound))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseOK{
+		Message: "unfollowed",
+	})
+}
+
+// @Router /users/{id}/following/categories [get]
+// @Summary Get followed categories
+// @Description Get categories the user follows
+// @Tags follow
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param filter query models.GetAllFollowsParams false "Filter"
+// @Success 200 {object} models.GetAllCategoriesResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) GetFollowedCategories(c *gin.Context) {
+	query, ok := followQuery(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.storage.Follow().GetFollowedCategories(c.Request.Context(), *query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, categoryResponse(result))
+}
+
+// followTarget returns the caller and the id in the path
+func (h *handlerV1) followTarget(c *gin.Context) (*utils.Payload, int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return nil, 0, false
+	}
+
+	payload, err := h.GetAuthPayload(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, 0, false
+	}
+
+	return payload, id, true
+}
+
+func followQuery(c *gin.Context) (*repo.GetFollowQuery, bool) {
+	var limit, page int
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err == nil {
+		limit, err = limitParam(c, maxListLimit)
+	}
+	if err == nil {
+		page, err = pageParam(c)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return nil, false
+	}
+
+	return &repo.GetFollowQuery{
+		Page:   page,
+		Limit:  limit,
+		UserID: userID,
+	}, true
+}
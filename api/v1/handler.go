@@ -4,6 +4,9 @@ import (
 	"errors"
 
 	"github.com/post/config"
+	"github.com/post/pkg/claps"
+	"github.com/post/pkg/related"
+	"github.com/post/pkg/views"
 	"github.com/post/storage"
 	"github.com/samandar2605/post/api/models"
 )
@@ -12,6 +15,9 @@ type handlerV1 struct {
 	cfg      *config.Config
 	storage  storage.StorageI
 	inMemory storage.InMemoryStorageI
+	claps    *claps.Buffer
+	views    *views.Counter
+	related  *related.Recommender
 }
 
 type HandlerV1Options struct {
@@ -25,11 +31,32 @@ func New(options *HandlerV1Options) *handlerV1 {
 		cfg:      options.Cfg,
 		storage:  options.Storage,
 		inMemory: options.InMemory,
+		claps:    claps.NewBuffer(options.Storage, options.InMemory),
+		views:    views.NewCounter(options.Storage, options.InMemory, options.Cfg.Views.Window),
+		related:  related.NewRecommender(options.Storage, options.InMemory),
 	}
 }
 
 var (
 	ErrForbidden = errors.New("forbidden")
+	ErrNotFound  = errors.New("not found")
+
+	ErrInvalidPostStatus = errors.New("invalid post status")
+	ErrInvalidPostSort   = errors.New("invalid post sort")
+	ErrCursorOrder       = errors.New("cursor pagination needs the list ordered by creation time")
+	ErrInvalidPage       = errors.New("page must be at least 1")
+	ErrPublishAtInPast   = errors.New("publish_at must be in the future")
+	ErrPublishAtStatus   = errors.New("publish_at can only be set on drafts and scheduled posts")
+	ErrTagTooLong        = errors.New("tags can not be longer than 64 characters")
+	ErrTagWithoutSlug    = errors.New("tags need a latin or cyrillic letter or a digit")
+
+	ErrDefaultReadingList = errors.New("the default reading list can not be renamed or deleted")
+	ErrFollowSelf         = errors.New("you can not follow yourself")
+
+	ErrInvalidParentComment = errors.New("parent comment is deleted or belongs to another post")
+	ErrCommentDeleted       = errors.New("comment is deleted")
+
+	ErrInvalidStatsRange = errors.New("from must not be after to, and the range can not be longer than 366 days")
 )
 
 func errorResponse(err error) *models.ErrorResponse {
@@ -36,7 +36,7 @@ func (h *handlerV1) CreateOrUpdateLike(c *gin.Context) {
 		return
 	}
 
-	err = h.storage.Like().CreateOrUpdate(&repo.Like{
+	err = h.storage.Like().CreateOrUpdate(c.Request.Context(), &repo.Like{
 		UserID: int64(payload.UserId),
 		PostID: req.PostID,
 		Status: req.Status,
@@ -74,7 +74,7 @@ func (h *handlerV1) GetLike(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.storage.Like().Get(int64(payload.UserId), int64(postID))
+	resp, err := h.storage.Like().Get(c.Request.Context(), int64(payload.UserId), int64(postID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
@@ -87,3 +87,54 @@ func (h *handlerV1) GetLike(c *gin.Context) {
 		Status: resp.Status,
 	})
 }
+
+// @Router /posts/{id}/likes [get]
+// @Summary Get likers
+// @Description Get users who liked the post
+// @Tags like
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param filter query models.GetAllLikersParams false "Filter"
+// @Success 200 {object} models.GetAllLikersResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) GetLikers(c *gin.Context) {
+	limit, err := limitParam(c, maxListLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	page, err := pageParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	post, ok := h.visiblePost(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.storage.Like().GetLikers(c.Request.Context(), repo.GetLikersQuery{
+		PostID: int64(post.Id),
+		Page:   page,
+		Limit:  limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := models.GetAllLikersResponse{
+		Users: make([]*models.UserProfile, 0),
+		Count: result.Count,
+	}
+	for _, u := range result.Users {
+		p := models.UserProfile(*u)
+		response.Users = append(response.Users, &p)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
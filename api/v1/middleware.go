@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"context"
 	"errors"
 	"net/http"
 
@@ -35,6 +36,35 @@ func (h *handlerV1) AuthMiddleware(c *gin.Context) {
 	c.Next()
 }
 
+func (h *handlerV1) OptionalAuthMiddleware(c *gin.Context) {
+	accessToken := c.GetHeader(authorizationHeaderKey)
+
+	if len(accessToken) != 0 {
+		payload, err := utils.VerifyToken(accessToken)
+		if err == nil {
+			c.Set(authorizationPayloadKey, payload)
+		}
+	}
+
+	c.Next()
+}
+
+// TimeoutMiddleware puts the configured deadline on the request context, so
+// the storage calls of a slow request are cancelled instead of piling up
+func (h *handlerV1) TimeoutMiddleware(c *gin.Context) {
+	timeout := h.cfg.PostConfig.RequestTimeout
+	if timeout <= 0 {
+		c.Next()
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+}
+
 func (m *handlerV1) GetAuthPayload(ctx *gin.Context) (*utils.Payload, error) {
 	i, exists := ctx.Get(authorizationPayloadKey)
 	if !exists {
@@ -49,13 +79,13 @@ func (m *handlerV1) GetAuthPayload(ctx *gin.Context) (*utils.Payload, error) {
 	return payload, nil
 }
 
-func (h *handlerV1) sendVerificationCode(key, email string) error {
+func (h *handlerV1) sendVerificationCode(ctx context.Context, key, email string) error {
 	code, err := utils.GenerateRandomCode(6)
 	if err != nil {
 		return err
 	}
 
-	err = h.inMemory.SetWithTTL(key+email, code, 1)
+	err = h.inMemory.SetWithTTL(ctx, key+email, code, 1)
 	if err != nil {
 		return err
 	}
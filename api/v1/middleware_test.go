@@ -0,0 +1,58 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/post/config"
+	"github.com/stretchr/testify/require"
+)
+
+func timeoutRouter(timeout time.Duration, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := &handlerV1{cfg: &config.Config{PostConfig: config.PostgresConfig{RequestTimeout: timeout}}}
+
+	router := gin.New()
+	router.GET("/", h.TimeoutMiddleware, handler)
+	return router
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	var ctx context.Context
+	router := timeoutRouter(time.Second, func(c *gin.Context) {
+		ctx = c.Request.Context()
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		require.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+		c.Status(http.StatusOK)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// the context is cancelled once the request is served
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+func TestTimeoutMiddlewareExpires(t *testing.T) {
+	router := timeoutRouter(10*time.Millisecond, func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		require.ErrorIs(t, c.Request.Context().Err(), context.DeadlineExceeded)
+		c.Status(http.StatusOK)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestTimeoutMiddlewareDisabled(t *testing.T) {
+	router := timeoutRouter(0, func(c *gin.Context) {
+		_, ok := c.Request.Context().Deadline()
+		require.False(t, ok)
+		c.Status(http.StatusOK)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -1,17 +1,25 @@
 package v1
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/post/api/models"
+	"github.com/post/pkg/content"
+	"github.com/post/pkg/utils"
+	"github.com/post/pkg/views"
+	"github.com/post/storage"
 	"github.com/post/storage/repo"
 )
 
 // @Router /posts/{id} [get]
 // @Summary Get post by id
-// @Description Get post by id
+// @Description Get post by id. A view is counted once per reader in a time window, views_count catches up a few seconds later
 // @Tags post
 // @Accept json
 // @Produce json
@@ -27,30 +35,111 @@ func (h *handlerV1) GetPost(c *gin.Context) {
 		return
 	}
 
-	if err = h.storage.Post().ViewsInc(id); err != nil {
+	resp, err := h.storage.Post().Get(c.Request.Context(), id)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: err.Error(),
 		})
 		return
 	}
-	resp, err := h.storage.Post().Get(id)
+
+	h.openPost(c, resp)
+}
+
+// GetPostBySlug serves GET /@{username}/{slug}. Old slugs redirect
+// to the current one with 301. It is not annotated for swagger because
+// swag can not parse "@" in router paths
+func (h *handlerV1) GetPostBySlug(c *gin.Context) {
+	usr, err := h.storage.User().GetByUsername(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(ErrNotFound))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resp, err := h.storage.Post().GetBySlug(c.Request.Context(), usr.Id, c.Param("slug"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(ErrNotFound))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if resp.Slug != c.Param("slug") {
+		c.Redirect(http.StatusMovedPermanently, "/v1/@"+usr.UserName+"/"+resp.Slug)
+		return
+	}
+
+	h.openPost(c, resp)
+}
+
+// openPost counts a view and writes the post to the response
+// if the caller is allowed to see it
+func (h *handlerV1) openPost(c *gin.Context, resp *repo.Post) {
+	payload, _ := h.GetAuthPayload(c)
+	if !canViewPost(payload, resp) {
+		c.JSON(http.StatusNotFound, errorResponse(ErrNotFound))
+		return
+	}
+
+	referrer := views.ReferrerDomain(c.Request.Referer(), c.Request.Host)
+	counted, err := h.views.Record(c.Request.Context(), resp.Id, viewer(c, payload), referrer)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: err.Error(),
 		})
 		return
 	}
-	usr, _ := h.storage.User().GetUserProfileInfo(resp.UserId)
+	if counted {
+		resp.ViewsCount++
+	}
+
+	if resp.DescriptionHtml == "" && resp.Description != "" {
+		_ = renderPostContent(resp)
+	}
+
+	if payload != nil {
+		resp.Bookmarked, _ = h.storage.ReadingList().IsBookmarked(c.Request.Context(), payload.UserId, resp.Id)
+		resp.MyClaps, _ = h.claps.Count(c.Request.Context(), resp.Id, payload.UserId)
+		if like, err := h.storage.Like().Get(c.Request.Context(), int64(payload.UserId), int64(resp.Id)); err == nil {
+			resp.MyReaction = likeReaction(like.Status)
+		}
+	}
+
+	usr, _ := h.storage.User().GetUserProfileInfo(c.Request.Context(), resp.UserId)
 	c.JSON(http.StatusOK, models.Post{
-		Id:          resp.Id,
-		Title:       resp.Title,
-		Description: resp.Description,
-		ImageUrl:    resp.ImageUrl,
-		UserId:      resp.UserId,
-		CategoryId:  resp.CategoryId,
-		UpdatedAt:   resp.UpdatedAt,
-		ViewsCount:  resp.ViewsCount,
-		CreatedAt:   resp.CreatedAt,
+		Id:              resp.Id,
+		Title:           resp.Title,
+		Slug:            resp.Slug,
+		Description:     resp.Description,
+		ContentFormat:   resp.ContentFormat,
+		DescriptionHtml: resp.DescriptionHtml,
+		ReadingTime:     resp.ReadingTime,
+		Excerpt:         resp.Excerpt,
+		TableOfContents: parsePostHeadingsModel(resp.TableOfContents),
+		ImageUrl:        resp.ImageUrl,
+		UserId:          resp.UserId,
+		CategoryId:      resp.CategoryId,
+		UpdatedAt:       resp.UpdatedAt,
+		ViewsCount:      resp.ViewsCount,
+		CreatedAt:       resp.CreatedAt,
+		Status:          resp.Status,
+		PublishedAt:     resp.PublishedAt,
+		PublishAt:       resp.PublishAt,
+		Tags:            resp.Tags,
+		Bookmarked:      resp.Bookmarked,
+		ClapsCount:      resp.ClapsCount,
+		MyClaps:         resp.MyClaps,
+		LikesCount:      resp.LikesCount,
+		DislikesCount:   resp.DislikesCount,
+		CommentsCount:   resp.CommentsCount,
+		BookmarksCount:  resp.BookmarksCount,
+		MyReaction:      resp.MyReaction,
 		User: models.UserProfile{
 			Id:              resp.UserId,
 			FirstName:       usr.FirstName,
@@ -70,7 +159,7 @@ func (h *handlerV1) GetPost(c *gin.Context) {
 // @Produce json
 // @Param post body models.CreatePost true "post"
 // @Success 201 {object} models.Post
-// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 func (h *handlerV1) CreatePost(c *gin.Context) {
 	var (
@@ -79,7 +168,7 @@ func (h *handlerV1) CreatePost(c *gin.Context) {
 
 	err := c.ShouldBindJSON(&req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error: err.Error(),
 		})
 		return
@@ -93,51 +182,99 @@ func (h *handlerV1) CreatePost(c *gin.Context) {
 		return
 	}
 
-	image, _ := h.storage.User().GetUserProfileInfo(usr.UserId)
-	resp, err := h.storage.Post().Create(&repo.Post{
-		Title:       req.Title,
-		Description: req.Description,
-		ImageUrl:    req.ImageUrl,
-		UserId:      usr.UserId,
-		CategoryId:  req.CategoryId,
+	if err := validateTags(req.Tags); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	status, err := scheduledStatus(repo.PostStatusDraft, req.Status, req.PublishAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	image, _ := h.storage.User().GetUserProfileInfo(c.Request.Context(), usr.UserId)
+	post := &repo.Post{
+		Title:         req.Title,
+		Description:   req.Description,
+		ContentFormat: req.ContentFormat,
+		ImageUrl:      req.ImageUrl,
+		UserId:        usr.UserId,
+		CategoryId:    req.CategoryId,
+		Status:        status,
+		PublishAt:     req.PublishAt,
 		User: repo.UserProfile{
 			Id:              usr.UserId,
 			FirstName:       usr.FirstName,
 			LastName:        usr.LastName,
 			Email:           usr.Email,
-			ProfileImageUrl:  image.ProfileImageUrl,
+			ProfileImageUrl: image.ProfileImageUrl,
 		},
-	})
+	}
+	if err := renderPostContent(post); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var resp *repo.Post
+	err = h.storage.WithTx(c.Request.Context(), func(tx storage.StorageI) error {
+		var err error
+		resp, err = tx.Post().Create(c.Request.Context(), post)
+		if err != nil {
+			return err
+		}
 
+		resp.Tags, err = setPostTags(c.Request.Context(), tx, resp.Id, req.Tags)
+		return err
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: err.Error(),
 		})
 		return
 	}
+	if resp.Status == repo.PostStatusPublished {
+		if err := h.related.Invalidate(c.Request.Context()); err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
 
 	c.JSON(http.StatusCreated, models.Post{
-		Id:          resp.Id,
-		Title:       resp.Title,
-		Description: resp.Description,
-		ImageUrl:    resp.ImageUrl,
-		UserId:      resp.UserId,
-		CategoryId:  resp.CategoryId,
-		ViewsCount:  resp.ViewsCount,
-		UpdatedAt:   resp.UpdatedAt,
-		CreatedAt:   resp.CreatedAt,
-		User:        models.UserProfile(resp.User),
+		Id:              resp.Id,
+		Title:           resp.Title,
+		Slug:            resp.Slug,
+		Description:     resp.Description,
+		ContentFormat:   resp.ContentFormat,
+		DescriptionHtml: resp.DescriptionHtml,
+		ReadingTime:     resp.ReadingTime,
+		Excerpt:         resp.Excerpt,
+		TableOfContents: parsePostHeadingsModel(resp.TableOfContents),
+		ImageUrl:        resp.ImageUrl,
+		UserId:          resp.UserId,
+		CategoryId:      resp.CategoryId,
+		ViewsCount:      resp.ViewsCount,
+		UpdatedAt:       resp.UpdatedAt,
+		CreatedAt:       resp.CreatedAt,
+		Status:          resp.Status,
+		PublishedAt:     resp.PublishedAt,
+		PublishAt:       resp.PublishAt,
+		Tags:            resp.Tags,
+		User:            models.UserProfile(resp.User),
 	})
 }
 
 // @Router /posts [get]
 // @Summary Get all posts
 // @Description Get all posts
+// @Description Pass an empty cursor to page by cursor, then next_cursor of the response as cursor.
+// @Description The count is only computed in page mode, unless with_count is set
 // @Tags post
 // @Accept json
 // @Produce json
 // @Param filter query models.GetAllPostsParams false "Filter"
 // @Success 200 {object} models.GetAllPostsResponse
+// @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 func (h *handlerV1) GetAllPost(c *gin.Context) {
 	req, err := postsParams(c)
@@ -148,11 +285,29 @@ func (h *handlerV1) GetAllPost(c *gin.Context) {
 		return
 	}
 
-	result, err := h.storage.Post().GetAll(repo.GetPostQuery{
-		Page:       req.Page,
-		Limit:      req.Limit,
+	cursor, err := cursorParams(c)
+	if err == nil && cursor.enabled && (req.Search != "" || req.Sort != "") {
+		err = ErrCursorOrder
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	payload, _ := h.GetAuthPayload(c)
+	page, limit := cursor.page(req.Page, req.Limit)
+	result, err := h.storage.Post().GetAll(c.Request.Context(), repo.GetPostQuery{
+		Page:       page,
+		Limit:      limit,
 		CategoryID: req.CategoryId,
 		UserID:     req.UserID,
+		Search:     req.Search,
+		Tag:        req.Tag,
+		Statuses:   visiblePostStatuses(payload, req.UserID, req.Status),
+		ViewerID:   viewerID(payload),
+		Sort:       req.Sort,
+		After:      cursor.after,
+		SkipCount:  !cursor.withCount,
 	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -161,29 +316,67 @@ func (h *handlerV1) GetAllPost(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, postsResponse(h, result))
+	var next string
+	result.Post, next = nextPage(cursor, result.Post, req.Limit, func(p *repo.Post) (time.Time, int) {
+		return p.CreatedAt, p.Id
+	})
+
+	response := postsResponse(result)
+	response.NextCursor = next
+	if err := h.setMyReactions(c.Request.Context(), response.Posts, viewerID(payload)); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// setMyReactions fills the caller's like reactions of the posts with a single query
+func (h *handlerV1) setMyReactions(ctx context.Context, posts []*models.PostSummary, viewerID int) error {
+	if viewerID == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, int64(post.Id))
+	}
+
+	reactions, err := h.storage.Like().GetUserReactions(ctx, ids, int64(viewerID))
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		post.MyReaction = reactions[int64(post.Id)]
+	}
+
+	return nil
+}
+
+func likeReaction(status bool) string {
+	if status {
+		return repo.LikeReactionLike
+	}
+	return repo.LikeReactionDislike
 }
 
 func postsParams(c *gin.Context) (*models.GetAllPostsParams, error) {
 	var (
-		limit              int = 10
-		page               int = 1
+		limit              int
+		page               int
 		err                error
 		CategoryId, UserId int
 	)
 
-	if c.Query("limit") != "" {
-		limit, err = strconv.Atoi(c.Query("limit"))
-		if err != nil {
-			return nil, err
-		}
+	limit, err = limitParam(c, maxListLimit)
+	if err != nil {
+		return nil, err
 	}
 
-	if c.Query("page") != "" {
-		page, err = strconv.Atoi(c.Query("page"))
-		if err != nil {
-			return nil, err
-		}
+	page, err = pageParam(c)
+	if err != nil {
+		return nil, err
 	}
 
 	if c.Query("category_id") != "" {
@@ -200,51 +393,132 @@ func postsParams(c *gin.Context) (*models.GetAllPostsParams, error) {
 		}
 	}
 
+	status := c.Query("status")
+	if status != "" && !isPostStatus(status) {
+		return nil, ErrInvalidPostStatus
+	}
+
+	sort := c.Query("sort")
+	if sort != "" && !isPostSort(sort) {
+		return nil, ErrInvalidPostSort
+	}
+
 	return &models.GetAllPostsParams{
 		Limit:      limit,
 		Page:       page,
 		CategoryId: CategoryId,
 		UserID:     UserId,
+		Search:     c.Query("search"),
+		Tag:        c.Query("tag"),
+		Status:     status,
+		Sort:       sort,
 	}, nil
 }
 
-func postsResponse(h *handlerV1, data *repo.GetAllPostResult) *models.GetAllPostsResponse {
+func postsResponse(data *repo.GetAllPostResult) *models.GetAllPostsResponse {
 	response := models.GetAllPostsResponse{
-		Posts: make([]*models.Post, 0),
+		Posts: make([]*models.PostSummary, 0),
 		Count: data.Count,
 	}
 
 	for _, post := range data.Post {
-		usr, _ := h.storage.User().GetUserProfileInfo(post.UserId)
-		if err := h.storage.Post().ViewsInc(post.Id); err != nil {
-			return nil
-		}
-		post.User = repo.UserProfile{
-			Id:              post.UserId,
-			FirstName:       usr.FirstName,
-			LastName:        usr.LastName,
-			Email:           usr.Email,
-			ProfileImageUrl: usr.ProfileImageUrl,
-		}
-		post.ViewsCount++
-		p := parsePostModel(post)
+		p := parsePostSummaryModel(post)
 		response.Posts = append(response.Posts, &p)
 	}
 
 	return &response
 }
 
+func setPostTags(ctx context.Context, strg storage.StorageI, postID int, names []string) ([]string, error) {
+	tags, err := strg.Tag().SetPostTags(ctx, postID, names)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, tag.Name)
+	}
+
+	return result, nil
+}
+
+// renderPostContent fills the fields derived from the post body:
+// the sanitized HTML, reading time, excerpt and table of contents
+func renderPostContent(post *repo.Post) error {
+	html, err := content.Render(post.ContentFormat, post.Description)
+	if err != nil {
+		return err
+	}
+
+	summary, err := content.Summarize(post.ContentFormat, post.Description)
+	if err != nil {
+		return err
+	}
+
+	post.DescriptionHtml = html
+	post.ReadingTime = summary.ReadingTime
+	post.Excerpt = summary.Excerpt
+	post.TableOfContents = make([]*repo.PostHeading, 0, len(summary.TableOfContents))
+	for _, heading := range summary.TableOfContents {
+		post.TableOfContents = append(post.TableOfContents, &repo.PostHeading{
+			Level: heading.Level,
+			Text:  heading.Text,
+			Id:    heading.Id,
+		})
+	}
+
+	return nil
+}
+
+func parsePostHeadingsModel(headings []*repo.PostHeading) []*models.PostHeading {
+	result := make([]*models.PostHeading, 0, len(headings))
+	for _, heading := range headings {
+		result = append(result, &models.PostHeading{
+			Level: heading.Level,
+			Text:  heading.Text,
+			Id:    heading.Id,
+		})
+	}
+	return result
+}
+
 func parsePostModel(post *repo.Post) models.Post {
+	var highlight *models.PostHighlight
+	if post.Highlight != nil {
+		highlight = &models.PostHighlight{
+			Title:       post.Highlight.Title,
+			Description: post.Highlight.Description,
+			Rank:        post.Highlight.Rank,
+		}
+	}
+
 	return models.Post{
-		Id:          post.Id,
-		Title:       post.Title,
-		Description: post.Description,
-		ImageUrl:    post.ImageUrl,
-		UserId:      post.UserId,
-		CategoryId:  post.CategoryId,
-		UpdatedAt:   post.UpdatedAt,
-		ViewsCount:  post.ViewsCount,
-		CreatedAt:   post.CreatedAt,
+		Id:              post.Id,
+		Title:           post.Title,
+		Slug:            post.Slug,
+		Description:     post.Description,
+		ContentFormat:   post.ContentFormat,
+		DescriptionHtml: post.DescriptionHtml,
+		ReadingTime:     post.ReadingTime,
+		Excerpt:         post.Excerpt,
+		TableOfContents: parsePostHeadingsModel(post.TableOfContents),
+		ImageUrl:        post.ImageUrl,
+		UserId:          post.UserId,
+		CategoryId:      post.CategoryId,
+		UpdatedAt:       post.UpdatedAt,
+		ViewsCount:      post.ViewsCount,
+		CreatedAt:       post.CreatedAt,
+		Status:          post.Status,
+		PublishedAt:     post.PublishedAt,
+		PublishAt:       post.PublishAt,
+		Tags:            post.Tags,
+		Bookmarked:      post.Bookmarked,
+		ClapsCount:      post.ClapsCount,
+		LikesCount:      post.LikesCount,
+		DislikesCount:   post.DislikesCount,
+		CommentsCount:   post.CommentsCount,
+		BookmarksCount:  post.BookmarksCount,
 		User: models.UserProfile{
 			Id:              post.UserId,
 			FirstName:       post.User.FirstName,
@@ -252,6 +526,53 @@ func parsePostModel(post *repo.Post) models.Post {
 			Email:           post.User.Email,
 			ProfileImageUrl: post.User.ProfileImageUrl,
 		},
+		Highlight: highlight,
+	}
+}
+
+// parsePostSummaryModel is parsePostModel for listings, without the body
+func parsePostSummaryModel(post *repo.Post) models.PostSummary {
+	var highlight *models.PostHighlight
+	if post.Highlight != nil {
+		highlight = &models.PostHighlight{
+			Title:       post.Highlight.Title,
+			Description: post.Highlight.Description,
+			Rank:        post.Highlight.Rank,
+		}
+	}
+
+	return models.PostSummary{
+		Id:              post.Id,
+		Title:           post.Title,
+		Slug:            post.Slug,
+		ContentFormat:   post.ContentFormat,
+		ReadingTime:     post.ReadingTime,
+		Excerpt:         post.Excerpt,
+		TableOfContents: parsePostHeadingsModel(post.TableOfContents),
+		ImageUrl:        post.ImageUrl,
+		UserId:          post.UserId,
+		CategoryId:      post.CategoryId,
+		UpdatedAt:       post.UpdatedAt,
+		ViewsCount:      post.ViewsCount,
+		CreatedAt:       post.CreatedAt,
+		Status:          post.Status,
+		PublishedAt:     post.PublishedAt,
+		PublishAt:       post.PublishAt,
+		Tags:            post.Tags,
+		Bookmarked:      post.Bookmarked,
+		ClapsCount:      post.ClapsCount,
+		LikesCount:      post.LikesCount,
+		DislikesCount:   post.DislikesCount,
+		CommentsCount:   post.CommentsCount,
+		BookmarksCount:  post.BookmarksCount,
+		User: models.UserProfile{
+			Id:              post.UserId,
+			FirstName:       post.User.FirstName,
+			LastName:        post.User.LastName,
+			Email:           post.User.Email,
+			ProfileImageUrl: post.User.ProfileImageUrl,
+		},
+		Highlight: highlight,
 	}
 }
 
@@ -262,16 +583,19 @@ func parsePostModel(post *repo.Post) models.Post {
 // @Accept json
 // @Produce json
 // @Param id path int true "ID"
-// @Param user body models.CreatePost true "post"
+// @Param user body models.UpdatePost true "post"
 // @Success 200 {object} models.Post
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /posts/{id} [put]
 func (h *handlerV1) UpdatePost(ctx *gin.Context) {
-	var b models.Post
+	var b models.UpdatePost
 
 	err := ctx.ShouldBindJSON(&b)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error: err.Error(),
 		})
 		return
@@ -291,20 +615,58 @@ func (h *handlerV1) UpdatePost(ctx *gin.Context) {
 		return
 	}
 
-	if payload.UserType != repo.UserTypeSuperadmin || payload.UserId!= h.storage.Post().GetUserInfo(id) {
-		ctx.JSON(http.StatusForbidden, errorResponse(ErrForbidden))
+	ownerID, ok := h.managedPostOwner(ctx, payload, id)
+	if !ok {
 		return
 	}
 
-	b.Id = id
-	post, err := h.storage.Post().Update(&repo.Post{
-		Id:          b.Id,
-		Title:       b.Title,
-		Description: b.Description,
-		ImageUrl:    b.ImageUrl,
-		UserId:      payload.UserId,
-		CategoryId:  b.CategoryId,
-		ViewsCount:  b.ViewsCount,
+	if err := validateTags(b.Tags); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	current, err := h.storage.Post().Get(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	status, err := scheduledStatus(current.Status, b.Status, b.PublishAt)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	format := b.ContentFormat
+	if format == "" {
+		format = current.ContentFormat
+	}
+
+	post := &repo.Post{
+		Id:            id,
+		Title:         b.Title,
+		Description:   b.Description,
+		ContentFormat: format,
+		ImageUrl:      b.ImageUrl,
+		UserId:        ownerID,
+		EditorId:      payload.UserId,
+		CategoryId:    b.CategoryId,
+		Status:        status,
+		PublishAt:     b.PublishAt,
+	}
+	if err := renderPostContent(post); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	err = h.storage.WithTx(ctx.Request.Context(), func(tx storage.StorageI) error {
+		post, err = tx.Post().Update(ctx.Request.Context(), post)
+		if err != nil {
+			return err
+		}
+
+		post.Tags, err = setPostTags(ctx.Request.Context(), tx, post.Id, b.Tags)
+		return err
 	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -312,7 +674,11 @@ func (h *handlerV1) UpdatePost(ctx *gin.Context) {
 		})
 		return
 	}
-	profil, _ := h.storage.User().GetUserProfileInfo(post.UserId)
+	if err := h.related.Invalidate(ctx.Request.Context()); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	profil, _ := h.storage.User().GetUserProfileInfo(ctx.Request.Context(), post.UserId)
 	post.User.Id = profil.Id
 	post.User.FirstName = profil.FirstName
 	post.User.LastName = profil.LastName
@@ -329,6 +695,8 @@ func (h *handlerV1) UpdatePost(ctx *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "ID"
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /posts/{id} [delete]
 func (h *handlerV1) DeletePost(ctx *gin.Context) {
@@ -345,19 +713,223 @@ func (h *handlerV1) DeletePost(ctx *gin.Context) {
 		return
 	}
 
-	if payload.UserType != repo.UserTypeSuperadmin || payload.UserId!= h.storage.Post().GetUserInfo(id) {
+	err = h.storage.WithTx(ctx.Request.Context(), func(tx storage.StorageI) error {
+		ownerID, err := tx.Post().GetUserInfo(ctx.Request.Context(), id)
+		if err != nil {
+			return err
+		}
+		if !canManagePost(payload, ownerID) {
+			return ErrForbidden
+		}
+		return tx.Post().Delete(ctx.Request.Context(), id)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, errorResponse(ErrNotFound))
+		return
+	} else if errors.Is(err, ErrForbidden) {
 		ctx.JSON(http.StatusForbidden, errorResponse(ErrForbidden))
 		return
-	}
-	
-	err = h.storage.Post().Delete(id)
-	if err != nil {
+	} else if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"message": "failed to Delete method",
 		})
 		return
 	}
+	if err := h.related.Invalidate(ctx.Request.Context()); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"message": "successful delete method",
 	})
 }
+
+// @Security ApiKeyAuth
+// @Summary Publish a post
+// @Description Publish a post
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.Post
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /posts/{id}/publish [post]
+func (h *handlerV1) PublishPost(ctx *gin.Context) {
+	h.changePostStatus(ctx, repo.PostStatusPublished)
+}
+
+// @Security ApiKeyAuth
+// @Summary Unpublish a post
+// @Description Move a post back to drafts
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.Post
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /posts/{id}/unpublish [post]
+func (h *handlerV1) UnpublishPost(ctx *gin.Context) {
+	h.changePostStatus(ctx, repo.PostStatusDraft)
+}
+
+func (h *handlerV1) changePostStatus(ctx *gin.Context, status string) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	payload, err := h.GetAuthPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if _, ok := h.managedPostOwner(ctx, payload, id); !ok {
+		return
+	}
+
+	post, err := h.storage.Post().UpdateStatus(ctx.Request.Context(), id, status)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if err := h.related.Invalidate(ctx.Request.Context()); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	usr, _ := h.storage.User().GetUserProfileInfo(ctx.Request.Context(), post.UserId)
+	if usr != nil {
+		post.User = repo.UserProfile{
+			Id:              post.UserId,
+			FirstName:       usr.FirstName,
+			LastName:        usr.LastName,
+			Email:           usr.Email,
+			ProfileImageUrl: usr.ProfileImageUrl,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, parsePostModel(post))
+}
+
+func isPostStatus(status string) bool {
+	switch status {
+	case repo.PostStatusDraft, repo.PostStatusScheduled, repo.PostStatusPublished,
+		repo.PostStatusUnlisted, repo.PostStatusArchived:
+		return true
+	}
+	return false
+}
+
+func isPostSort(sort string) bool {
+	switch sort {
+	case repo.PostSortTrending, repo.PostSortTopWeek, repo.PostSortTopMonth, repo.PostSortMostViewed:
+		return true
+	}
+	return false
+}
+
+// scheduledStatus turns a post with a future publish_at into a scheduled one.
+// current is the status the post has, status the one requested. Only drafts
+// and scheduled posts can be scheduled, and only if no other status is asked for
+func scheduledStatus(current, status string, publishAt *time.Time) (string, error) {
+	if publishAt == nil {
+		return status, nil
+	}
+	if (current != repo.PostStatusDraft && current != repo.PostStatusScheduled) ||
+		(status != "" && status != repo.PostStatusDraft) {
+		return "", ErrPublishAtStatus
+	}
+	if !publishAt.After(time.Now()) {
+		return "", ErrPublishAtInPast
+	}
+	return repo.PostStatusScheduled, nil
+}
+
+// canManagePost reports whether the caller is the author of the post or a superadmin
+func canManagePost(payload *utils.Payload, ownerID int) bool {
+	if payload == nil {
+		return false
+	}
+	return payload.UserType == repo.UserTypeSuperadmin || payload.UserId == ownerID
+}
+
+// managedPostOwner returns the author of the post if the caller can manage
+// it. It writes 404 if the post does not exist and 403 if it is someone else's
+func (h *handlerV1) managedPostOwner(c *gin.Context, payload *utils.Payload, id int) (int, bool) {
+	ownerID, err := h.storage.Post().GetUserInfo(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, errorResponse(ErrNotFound))
+		return 0, false
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return 0, false
+	}
+
+	if !canManagePost(payload, ownerID) {
+		c.JSON(http.StatusForbidden, errorResponse(ErrForbidden))
+		return 0, false
+	}
+	return ownerID, true
+}
+
+// canViewPost hides drafts and archived posts from everyone but the author and superadmins.
+// Unlisted posts stay reachable by direct link
+func canViewPost(payload *utils.Payload, post *repo.Post) bool {
+	if post.Status == repo.PostStatusPublished || post.Status == repo.PostStatusUnlisted {
+		return true
+	}
+	return canManagePost(payload, post.UserId)
+}
+
+// visiblePostStatuses returns the statuses a listing may contain for the caller.
+// Only the author (listing their own posts) and superadmins see non published posts
+func visiblePostStatuses(payload *utils.Payload, userID int, status string) []string {
+	if !canManagePost(payload, userID) {
+		return []string{repo.PostStatusPublished}
+	}
+	if status != "" {
+		return []string{status}
+	}
+	return nil
+}
+
+// viewer identifies the caller for view counting
+func viewer(c *gin.Context, payload *utils.Payload) string {
+	if payload != nil {
+		return views.UserViewer(payload.UserId)
+	}
+	return views.AnonymousViewer(c.ClientIP(), c.Request.UserAgent())
+}
+
+// visiblePost returns the post in the path if the caller can see it
+func (h *handlerV1) visiblePost(c *gin.Context) (*repo.Post, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return nil, false
+	}
+
+	post, err := h.storage.Post().Get(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(ErrNotFound))
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, false
+	}
+
+	payload, _ := h.GetAuthPayload(c)
+	if !canViewPost(payload, post) {
+		c.JSON(http.StatusNotFound, errorResponse(ErrNotFound))
+		return nil, false
+	}
+
+	return post, true
+}
@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/post/pkg/utils"
+	"github.com/post/storage"
+	"github.com/post/storage/repo"
+)
+
+// The fakes below count the storage calls of the handler. The queries of
+// each call are counted against the database by TestGetAllPostQueries of
+// storage/postgres
+
+type countingPosts struct {
+	repo.PostStorageI
+	calls *int
+}
+
+func (f *countingPosts) GetAll(ctx context.Context, param repo.GetPostQuery) (*repo.GetAllPostResult, error) {
+	*f.calls++
+
+	result := repo.GetAllPostResult{Count: 1000}
+	for i := 0; i < param.Limit; i++ {
+		result.Post = append(result.Post, &repo.Post{
+			Id:     i + 1,
+			UserId: i%7 + 1,
+			Status: repo.PostStatusPublished,
+			User:   repo.UserProfile{Id: i%7 + 1, FirstName: "author"},
+		})
+	}
+	return &result, nil
+}
+
+type countingLikes struct {
+	repo.LikeStorageI
+	calls *int
+}
+
+func (f *countingLikes) GetUserReactions(ctx context.Context, postIDs []int64, userID int64) (map[int64]string, error) {
+	*f.calls++
+	return map[int64]string{}, nil
+}
+
+type countingUsers struct {
+	repo.UserStorageI
+	calls *int
+}
+
+func (f *countingUsers) GetUserProfileInfo(ctx context.Context, usrId int) (*repo.User, error) {
+	*f.calls++
+	return &repo.User{Id: usrId}, nil
+}
+
+type countingStorage struct {
+	storage.StorageI
+	calls int
+}
+
+func (f *countingStorage) Post() repo.PostStorageI {
+	return &countingPosts{calls: &f.calls}
+}
+
+func (f *countingStorage) Like() repo.LikeStorageI {
+	return &countingLikes{calls: &f.calls}
+}
+
+func (f *countingStorage) User() repo.UserStorageI {
+	return &countingUsers{calls: &f.calls}
+}
+
+// BenchmarkGetAllPostCalls reports the storage calls the handler makes for a
+// posts page, which must not grow with the page size
+func BenchmarkGetAllPostCalls(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+
+	for _, limit := range []int{10, 50} {
+		b.Run("limit="+strconv.Itoa(limit), func(b *testing.B) {
+			strg := &countingStorage{}
+			h := &handlerV1{storage: strg}
+
+			for i := 0; i < b.N; i++ {
+				w := httptest.NewRecorder()
+				c, _ := gin.CreateTestContext(w)
+				c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts?limit="+strconv.Itoa(limit), nil)
+				c.Set(authorizationPayloadKey, &utils.Payload{UserId: 1})
+
+				h.GetAllPost(c)
+				if w.Code != http.StatusOK {
+					b.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+				}
+			}
+
+			b.ReportMetric(float64(strg.calls)/float64(b.N), "calls/op")
+		})
+	}
+}
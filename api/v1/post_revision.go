@@ -0,0 +1,324 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/post/api/models"
+	"github.com/post/pkg/diff"
+	"github.com/post/storage/repo"
+)
+
+// @Security ApiKeyAuth
+// @Router /posts/{id}/revisions [get]
+// @Summary Get post revisions
+// @Description Get the edit history of a post, newest first
+// @Tags post-revision
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param filter query models.GetAllPostRevisionsParams false "Filter"
+// @Success 200 {object} models.GetAllPostRevisionsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) GetPostRevisions(c *gin.Context) {
+	postID, ok := h.postRevisionAccess(c)
+	if !ok {
+		return
+	}
+
+	req, err := revisionsParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := h.storage.PostRevision().GetAll(c.Request.Context(), repo.GetPostRevisionQuery{
+		Page:   req.Page,
+		Limit:  req.Limit,
+		PostId: postID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := models.GetAllPostRevisionsResponse{
+		Revisions: make([]*models.PostRevision, 0),
+		Count:     result.Count,
+	}
+	for _, revision := range result.Revisions {
+		r := parsePostRevisionModel(revision)
+		response.Revisions = append(response.Revisions, &r)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Security ApiKeyAuth
+// @Router /posts/{id}/revisions/{rev} [get]
+// @Summary Get post revision
+// @Description Get a single revision of a post
+// @Tags post-revision
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param rev path int true "Revision"
+// @Success 200 {object} models.PostRevision
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) GetPostRevision(c *gin.Context) {
+	postID, ok := h.postRevisionAccess(c)
+	if !ok {
+		return
+	}
+
+	revision, ok := h.getPostRevision(c, postID, c.Param("rev"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, parsePostRevisionModel(revision))
+}
+
+// @Security ApiKeyAuth
+// @Router /posts/{id}/revisions/{rev}/diff [get]
+// @Summary Diff post revisions
+// @Description Line based diff from the "against" revision (previous one by default) to rev
+// @Tags post-revision
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param rev path int true "Revision"
+// @Param against query int false "Revision to compare with"
+// @Success 200 {object} models.PostRevisionDiff
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) DiffPostRevision(c *gin.Context) {
+	postID, ok := h.postRevisionAccess(c)
+	if !ok {
+		return
+	}
+
+	to, ok := h.getPostRevision(c, postID, c.Param("rev"))
+	if !ok {
+		return
+	}
+
+	against := c.Query("against")
+	if against == "" {
+		against = strconv.Itoa(to.Revision - 1)
+	}
+
+	// the first revision is compared with an empty post
+	from := &repo.PostRevision{PostId: postID}
+	if against != "0" {
+		from, ok = h.getPostRevision(c, postID, against)
+		if !ok {
+			return
+		}
+	}
+
+	title, err := diff.Lines(from.Title, to.Title)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	description, err := diff.Lines(from.Description, to.Description)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PostRevisionDiff{
+		From:        from.Revision,
+		To:          to.Revision,
+		Title:       parseDiffModel(title),
+		Description: parseDiffModel(description),
+	})
+}
+
+// @Security ApiKeyAuth
+// @Router /posts/{id}/revisions/{rev}/restore [post]
+// @Summary Restore post revision
+// @Description Restore the content of a revision. The restore is recorded as a new revision
+// @Tags post-revision
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param rev path int true "Revision"
+// @Success 200 {object} models.Post
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) RestorePostRevision(c *gin.Context) {
+	postID, ok := h.postRevisionAccess(c)
+	if !ok {
+		return
+	}
+
+	revision, ok := h.getPostRevision(c, postID, c.Param("rev"))
+	if !ok {
+		return
+	}
+
+	payload, err := h.GetAuthPayload(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	post, err := h.storage.Post().Get(c.Request.Context(), postID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	restored := &repo.Post{
+		Id:            postID,
+		Title:         revision.Title,
+		Description:   revision.Description,
+		ContentFormat: revision.ContentFormat,
+		ImageUrl:      revision.ImageUrl,
+		UserId:        post.UserId,
+		CategoryId:    revision.CategoryId,
+		EditorId:      payload.UserId,
+	}
+	if err := renderPostContent(restored); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = h.storage.Post().Update(c.Request.Context(), restored)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if err := h.related.Invalidate(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	post, err = h.storage.Post().Get(c.Request.Context(), postID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	usr, _ := h.storage.User().GetUserProfileInfo(c.Request.Context(), post.UserId)
+	if usr != nil {
+		post.User = repo.UserProfile{
+			Id:              post.UserId,
+			FirstName:       usr.FirstName,
+			LastName:        usr.LastName,
+			Email:           usr.Email,
+			ProfileImageUrl: usr.ProfileImageUrl,
+		}
+	}
+
+	c.JSON(http.StatusOK, parsePostModel(post))
+}
+
+// postRevisionAccess parses the post id and makes sure the caller
+// is the author of the post or a superadmin
+func (h *handlerV1) postRevisionAccess(c *gin.Context) (int, bool) {
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return 0, false
+	}
+
+	payload, err := h.GetAuthPayload(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return 0, false
+	}
+
+	if _, ok := h.managedPostOwner(c, payload, postID); !ok {
+		return 0, false
+	}
+
+	return postID, true
+}
+
+func (h *handlerV1) getPostRevision(c *gin.Context, postID int, rev string) (*repo.PostRevision, bool) {
+	revision, err := strconv.Atoi(rev)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return nil, false
+	}
+
+	result, err := h.storage.PostRevision().Get(c.Request.Context(), postID, revision)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(ErrNotFound))
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, false
+	}
+
+	return result, true
+}
+
+func revisionsParams(c *gin.Context) (*models.GetAllPostRevisionsParams, error) {
+	limit, err := limitParam(c, maxListLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := pageParam(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GetAllPostRevisionsParams{
+		Limit: limit,
+		Page:  page,
+	}, nil
+}
+
+func parsePostRevisionModel(revision *repo.PostRevision) models.PostRevision {
+	result := models.PostRevision{
+		Id:            revision.Id,
+		PostId:        revision.PostId,
+		Revision:      revision.Revision,
+		EditorId:      revision.EditorId,
+		Title:         revision.Title,
+		Description:   revision.Description,
+		ContentFormat: revision.ContentFormat,
+		ImageUrl:      revision.ImageUrl,
+		CategoryId:    revision.CategoryId,
+		CreatedAt:     revision.CreatedAt,
+	}
+	if revision.EditorId != nil {
+		result.Editor = &models.UserProfile{
+			Id:              revision.Editor.Id,
+			FirstName:       revision.Editor.FirstName,
+			LastName:        revision.Editor.LastName,
+			Email:           revision.Editor.Email,
+			ProfileImageUrl: revision.Editor.ProfileImageUrl,
+		}
+	}
+
+	return result
+}
+
+func parseDiffModel(lines []diff.Line) []*models.DiffLine {
+	result := make([]*models.DiffLine, 0, len(lines))
+	for _, line := range lines {
+		result = append(result, &models.DiffLine{
+			Op:   line.Op,
+			Text: line.Text,
+		})
+	}
+	return result
+}
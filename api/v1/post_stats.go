@@ -0,0 +1,225 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/post/api/models"
+	"github.com/post/storage/repo"
+)
+
+const (
+	statsDayLayout   = "2006-01-02"
+	defaultStatsDays = 30
+	maxStatsDays     = 366
+	statsTopPosts    = 10
+)
+
+// @Security ApiKeyAuth
+// @Router /posts/{id}/stats [get]
+// @Summary Get post stats
+// @Description Daily stats of the post, only for its author. Days are in UTC, the last 30 days by default
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param filter query models.GetPostStatsParams false "Filter"
+// @Success 200 {object} models.PostStatsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) GetPostStats(c *gin.Context) {
+	from, to, err := statsRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	post, ok := h.visiblePost(c)
+	if !ok {
+		return
+	}
+
+	payload, err := h.GetAuthPayload(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if !canManagePost(payload, post.UserId) {
+		c.JSON(http.StatusForbidden, errorResponse(ErrForbidden))
+		return
+	}
+
+	days, err := h.storage.PostStats().GetDaily(c.Request.Context(), post.Id, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, postStatsResponse(post.Id, from, to, days))
+}
+
+// @Security ApiKeyAuth
+// @Router /me/stats [get]
+// @Summary Get my stats
+// @Description Stats of all the caller's posts with the most viewed ones. Days are in UTC, the last 30 days by default
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Param filter query models.GetPostStatsParams false "Filter"
+// @Success 200 {object} models.AuthorStatsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) GetMyStats(c *gin.Context) {
+	from, to, err := statsRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	payload, err := h.GetAuthPayload(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	stats, err := h.storage.PostStats().GetAuthorStats(c.Request.Context(), payload.UserId, from, to, statsTopPosts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := models.AuthorStatsResponse{
+		From:       from.Format(statsDayLayout),
+		To:         to.Format(statsDayLayout),
+		PostsCount: stats.PostsCount,
+		Totals:     parsePostStatsTotals(stats.PostStatsTotals),
+		TopPosts:   make([]*models.PostStatsSummary, 0, len(stats.TopPosts)),
+	}
+	for _, p := range stats.TopPosts {
+		response.TopPosts = append(response.TopPosts, &models.PostStatsSummary{
+			PostID: p.PostID,
+			Title:  p.Title,
+			Stats:  parsePostStatsTotals(p.PostStatsTotals),
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Router /posts/{id}/read [post]
+// @Summary Mark a post as read
+// @Description Clients call it when the reader reaches the end of the post. Counted once a day per reader
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.ResponseOK
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) ReadPost(c *gin.Context) {
+	post, ok := h.visiblePost(c)
+	if !ok {
+		return
+	}
+
+	payload, _ := h.GetAuthPayload(c)
+	if err := h.views.RecordRead(c.Request.Context(), post.Id, viewer(c, payload)); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseOK{
+		Message: "read",
+	})
+}
+
+// statsRange parses the from and to days of the query
+func statsRange(c *gin.Context) (time.Time, time.Time, error) {
+	var (
+		to  = time.Now().UTC().Truncate(24 * time.Hour)
+		err error
+	)
+
+	if c.Query("to") != "" {
+		to, err = time.Parse(statsDayLayout, c.Query("to"))
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+
+	from := to.AddDate(0, 0, -(defaultStatsDays - 1))
+	if c.Query("from") != "" {
+		from, err = time.Parse(statsDayLayout, c.Query("from"))
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+
+	if from.After(to) || to.Sub(from) >= maxStatsDays*24*time.Hour {
+		return time.Time{}, time.Time{}, ErrInvalidStatsRange
+	}
+
+	return from, to, nil
+}
+
+// postStatsResponse sums up the days and fills the days without stats with zeros
+func postStatsResponse(postID int, from, to time.Time, days []*repo.PostDailyStats) models.PostStatsResponse {
+	response := models.PostStatsResponse{
+		PostID: postID,
+		From:   from.Format(statsDayLayout),
+		To:     to.Format(statsDayLayout),
+		Totals: models.PostStatsTotals{
+			Referrers: make(map[string]int),
+		},
+		Days: make([]*models.PostDailyStats, 0),
+	}
+
+	byDay := make(map[string]*repo.PostDailyStats, len(days))
+	for _, d := range days {
+		byDay[d.Day.Format(statsDayLayout)] = d
+	}
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		stats := models.PostDailyStats{
+			Day: day.Format(statsDayLayout),
+		}
+		if d, ok := byDay[stats.Day]; ok {
+			stats.PostStatsTotals = parsePostStatsTotals(repo.PostStatsTotals{
+				Views:         d.Views,
+				UniqueReaders: d.UniqueReaders,
+				Reads:         d.Reads,
+				Likes:         d.Likes,
+				Comments:      d.Comments,
+				Referrers:     d.Referrers,
+			})
+		}
+
+		response.Totals.Views += stats.Views
+		response.Totals.UniqueReaders += stats.UniqueReaders
+		response.Totals.Reads += stats.Reads
+		response.Totals.Likes += stats.Likes
+		response.Totals.Comments += stats.Comments
+		for domain, n := range stats.Referrers {
+			response.Totals.Referrers[domain] += n
+		}
+
+		response.Days = append(response.Days, &stats)
+	}
+
+	return response
+}
+
+func parsePostStatsTotals(t repo.PostStatsTotals) models.PostStatsTotals {
+	return models.PostStatsTotals{
+		Views:         t.Views,
+		UniqueReaders: t.UniqueReaders,
+		Reads:         t.Reads,
+		Likes:         t.Likes,
+		Comments:      t.Comments,
+		Referrers:     t.Referrers,
+	}
+}
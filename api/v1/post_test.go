@@ -0,0 +1,38 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/post/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledStatus(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		current   string
+		status    string
+		publishAt *time.Time
+		want      string
+		err       error
+	}{
+		{"no publish_at", repo.PostStatusPublished, repo.PostStatusArchived, nil, repo.PostStatusArchived, nil},
+		{"draft", repo.PostStatusDraft, "", &future, repo.PostStatusScheduled, nil},
+		{"reschedule", repo.PostStatusScheduled, "", &future, repo.PostStatusScheduled, nil},
+		{"in the past", repo.PostStatusDraft, repo.PostStatusDraft, &past, "", ErrPublishAtInPast},
+		{"published post", repo.PostStatusPublished, "", &future, "", ErrPublishAtStatus},
+		{"published status", repo.PostStatusDraft, repo.PostStatusPublished, &future, "", ErrPublishAtStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := scheduledStatus(tt.current, tt.status, tt.publishAt)
+			require.ErrorIs(t, err, tt.err)
+			require.Equal(t, tt.want, status)
+		})
+	}
+}
@@ -0,0 +1,483 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/post/api/models"
+	"github.com/post/pkg/utils"
+	"github.com/post/storage/repo"
+)
+
+// defaultReadingListParam can be used instead of the id of the caller's "Saved" list
+const defaultReadingListParam = "saved"
+
+// @Security ApiKeyAuth
+// @Router /me/lists [get]
+// @Summary Get my reading lists
+// @Description Get reading lists of the caller. The default "Saved" list comes first
+// @Tags reading-list
+// @Accept json
+// @Produce json
+// @Param filter query models.GetAllReadingListsParams false "Filter"
+// @Success 200 {object} models.GetAllReadingListsResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) GetMyReadingLists(c *gin.Context) {
+	payload, err := h.GetAuthPayload(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if _, err := h.storage.ReadingList().GetOrCreateDefault(c.Request.Context(), payload.UserId); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	h.readingLists(c, payload.UserId, false)
+}
+
+// @Router /users/{id}/lists [get]
+// @Summary Get user reading lists
+// @Description Get public reading lists of a user. The owner sees private lists too
+// @Tags reading-list
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param filter query models.GetAllReadingListsParams false "Filter"
+// @Success 200 {object} models.GetAllReadingListsResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) GetUserReadingLists(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	payload, _ := h.GetAuthPayload(c)
+	h.readingLists(c, userID, payload == nil || payload.UserId != userID)
+}
+
+func (h *handlerV1) readingLists(c *gin.Context, userID int, publicOnly bool) {
+	req, err := readingListsParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := h.storage.ReadingList().GetAll(c.Request.Context(), repo.GetReadingListQuery{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		UserId:     userID,
+		PublicOnly: publicOnly,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, readingListsResponse(result))
+}
+
+// @Router /lists/{id} [get]
+// @Summary Get reading list
+// @Description Get a public reading list, or a private one of the caller
+// @Tags reading-list
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.ReadingList
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) GetReadingList(c *gin.Context) {
+	list, ok := h.visibleReadingList(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, parseReadingListModel(list))
+}
+
+// @Router /lists/{id}/posts [get]
+// @Summary Get reading list posts
+// @Description Get posts saved in a public reading list, or a private one of the caller
+// @Tags reading-list
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param filter query models.GetAllReadingListsParams false "Filter"
+// @Success 200 {object} models.GetAllPostsResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) GetReadingListPosts(c *gin.Context) {
+	list, ok := h.visibleReadingList(c)
+	if !ok {
+		return
+	}
+
+	req, err := readingListsParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	payload, _ := h.GetAuthPayload(c)
+	result, err := h.storage.Post().GetAll(c.Request.Context(), repo.GetPostQuery{
+		Page:          req.Page,
+		Limit:         req.Limit,
+		ReadingListID: list.Id,
+		ViewerID:      viewerID(payload),
+		Statuses:      []string{repo.PostStatusPublished, repo.PostStatusUnlisted},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, postsResponse(result))
+}
+
+// @Security ApiKeyAuth
+// @Router /me/lists [post]
+// @Summary Create a reading list
+// @Description Create a reading list
+// @Tags reading-list
+// @Accept json
+// @Produce json
+// @Param list body models.CreateReadingList true "Reading list"
+// @Success 201 {object} models.ReadingList
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) CreateReadingList(c *gin.Context) {
+	var req models.CreateReadingList
+
+	payload, err := h.GetAuthPayload(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	list, err := h.storage.ReadingList().Create(c.Request.Context(), &repo.ReadingList{
+		UserId:      payload.UserId,
+		Name:        req.Name,
+		Description: req.Description,
+		Visibility:  req.Visibility,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, parseReadingListModel(list))
+}
+
+// @Security ApiKeyAuth
+// @Router /me/lists/{id} [put]
+// @Summary Update a reading list
+// @Description Update a reading list. Use "saved" as id for the default list, which can not be renamed
+// @Tags reading-list
+// @Accept json
+// @Produce json
+// @Param id path string true "ID"
+// @Param list body models.CreateReadingList true "Reading list"
+// @Success 200 {object} models.ReadingList
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) UpdateReadingList(c *gin.Context) {
+	var req models.CreateReadingList
+
+	list, ok := h.ownReadingList(c)
+	if !ok {
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if list.IsDefault && req.Name != list.Name {
+		c.JSON(http.StatusBadRequest, errorResponse(ErrDefaultReadingList))
+		return
+	}
+
+	list.Name = req.Name
+	list.Description = req.Description
+	list.Visibility = req.Visibility
+	list, err := h.storage.ReadingList().Update(c.Request.Context(), list)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, parseReadingListModel(list))
+}
+
+// @Security ApiKeyAuth
+// @Router /me/lists/{id} [delete]
+// @Summary Delete a reading list
+// @Description Delete a reading list. The default list can not be deleted
+// @Tags reading-list
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.ResponseOK
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) DeleteReadingList(c *gin.Context) {
+	list, ok := h.ownReadingList(c)
+	if !ok {
+		return
+	}
+
+	if list.IsDefault {
+		c.JSON(http.StatusBadRequest, errorResponse(ErrDefaultReadingList))
+		return
+	}
+
+	if err := h.storage.ReadingList().Delete(c.Request.Context(), list.Id); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseOK{
+		Message: "successfully deleted",
+	})
+}
+
+// @Security ApiKeyAuth
+// @Router /me/lists/{id}/posts/{post_id} [post]
+// @Summary Save a post to a reading list
+// @Description Save a post to a reading list. Use "saved" as id to bookmark the post
+// @Tags reading-list
+// @Accept json
+// @Produce json
+// @Param id path string true "ID"
+// @Param post_id path int true "Post ID"
+// @Success 200 {object} models.ReadingList
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) AddReadingListPost(c *gin.Context) {
+	list, ok := h.ownReadingList(c)
+	if !ok {
+		return
+	}
+
+	postID, err := strconv.Atoi(c.Param("post_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	post, err := h.storage.Post().Get(c.Request.Context(), postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(ErrNotFound))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	payload, _ := h.GetAuthPayload(c)
+	if !canViewPost(payload, post) {
+		c.JSON(http.StatusNotFound, errorResponse(ErrNotFound))
+		return
+	}
+
+	if err := h.storage.ReadingList().AddPost(c.Request.Context(), list.Id, post.Id); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	h.readingListResponse(c, list.Id)
+}
+
+// @Security ApiKeyAuth
+// @Router /me/lists/{id}/posts/{post_id} [delete]
+// @Summary Remove a post from a reading list
+// @Description Remove a post from a reading list
+// @Tags reading-list
+// @Accept json
+// @Produce json
+// @Param id path string true "ID"
+// @Param post_id path int true "Post ID"
+// @Success 200 {object} models.ReadingList
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) RemoveReadingListPost(c *gin.Context) {
+	list, ok := h.ownReadingList(c)
+	if !ok {
+		return
+	}
+
+	postID, err := strconv.Atoi(c.Param("post_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := h.storage.ReadingList().RemovePost(c.Request.Context(), list.Id, postID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(ErrNotFound))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	h.readingListResponse(c, list.Id)
+}
+
+// readingListResponse writes the list with its updated posts count
+func (h *handlerV1) readingListResponse(c *gin.Context, id int) {
+	list, err := h.storage.ReadingList().Get(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, parseReadingListModel(list))
+}
+
+// ownReadingList resolves the list in the path and makes sure
+// it belongs to the caller
+func (h *handlerV1) ownReadingList(c *gin.Context) (*repo.ReadingList, bool) {
+	payload, err := h.GetAuthPayload(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, false
+	}
+
+	if c.Param("id") == defaultReadingListParam {
+		list, err := h.storage.ReadingList().GetOrCreateDefault(c.Request.Context(), payload.UserId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+			return nil, false
+		}
+		return list, true
+	}
+
+	list, ok := h.getReadingList(c)
+	if !ok {
+		return nil, false
+	}
+
+	if list.UserId != payload.UserId {
+		c.JSON(http.StatusForbidden, errorResponse(ErrForbidden))
+		return nil, false
+	}
+
+	return list, true
+}
+
+// visibleReadingList resolves the list in the path and hides
+// private lists from everyone but the owner
+func (h *handlerV1) visibleReadingList(c *gin.Context) (*repo.ReadingList, bool) {
+	list, ok := h.getReadingList(c)
+	if !ok {
+		return nil, false
+	}
+
+	payload, _ := h.GetAuthPayload(c)
+	if !canViewReadingList(payload, list) {
+		c.JSON(http.StatusNotFound, errorResponse(ErrNotFound))
+		return nil, false
+	}
+
+	return list, true
+}
+
+func (h *handlerV1) getReadingList(c *gin.Context) (*repo.ReadingList, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return nil, false
+	}
+
+	list, err := h.storage.ReadingList().Get(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(ErrNotFound))
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, false
+	}
+
+	return list, true
+}
+
+func canViewReadingList(payload *utils.Payload, list *repo.ReadingList) bool {
+	if list.Visibility == repo.ReadingListPublic {
+		return true
+	}
+	return payload != nil && payload.UserId == list.UserId
+}
+
+// viewerID returns the id of the authenticated caller or 0 for guests
+func viewerID(payload *utils.Payload) int {
+	if payload == nil {
+		return 0
+	}
+	return payload.UserId
+}
+
+func readingListsParams(c *gin.Context) (*models.GetAllReadingListsParams, error) {
+	limit, err := limitParam(c, maxListLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := pageParam(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GetAllReadingListsParams{
+		Limit: limit,
+		Page:  page,
+	}, nil
+}
+
+func readingListsResponse(data *repo.GetAllReadingListsResult) *models.GetAllReadingListsResponse {
+	response := models.GetAllReadingListsResponse{
+		ReadingLists: make([]*models.ReadingList, 0),
+		Count:        data.Count,
+	}
+
+	for _, list := range data.ReadingLists {
+		l := parseReadingListModel(list)
+		response.ReadingLists = append(response.ReadingLists, &l)
+	}
+
+	return &response
+}
+
+func parseReadingListModel(list *repo.ReadingList) models.ReadingList {
+	return models.ReadingList{
+		Id:          list.Id,
+		UserId:      list.UserId,
+		Name:        list.Name,
+		Description: list.Description,
+		Visibility:  list.Visibility,
+		IsDefault:   list.IsDefault,
+		PostsCount:  list.PostsCount,
+		CreatedAt:   list.CreatedAt,
+		UpdatedAt:   list.UpdatedAt,
+	}
+}
@@ -0,0 +1,114 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/post/pkg/related"
+	"github.com/post/storage/repo"
+)
+
+// @Router /posts/{id}/related [get]
+// @Summary Get related posts
+// @Description Get published posts related to a post by category, tags, terms and readers who liked both
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param limit query int false "Limit" default(5)
+// @Success 200 {object} models.GetAllPostsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) GetRelatedPosts(c *gin.Context) {
+	limit := 5
+	if c.Query("limit") != "" {
+		var err error
+		limit, err = strconv.Atoi(c.Query("limit"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+	if limit <= 0 || limit > related.MaxRelated {
+		limit = related.MaxRelated
+	}
+
+	post, ok := h.visiblePost(c)
+	if !ok {
+		return
+	}
+
+	ids, err := h.related.Get(c.Request.Context(), post.Id, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	payload, _ := h.GetAuthPayload(c)
+	result, err := h.relatedPosts(c.Request.Context(), ids, viewerID(payload))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := postsResponse(result)
+	if err := h.setMyReactions(c.Request.Context(), response.Posts, viewerID(payload)); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// relatedPosts loads the ranked posts, their authors and the viewer's
+// bookmarks in batches, mostly from the cache. Posts unpublished or deleted
+// since the ranking was cached are left out
+func (h *handlerV1) relatedPosts(ctx context.Context, ids []int, viewerID int) (*repo.GetAllPostResult, error) {
+	posts, err := h.storage.Post().GetMany(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &repo.GetAllPostResult{Post: make([]*repo.Post, 0, len(ids))}
+	authorIDs := make([]int, 0, len(ids))
+	for _, id := range ids {
+		post, ok := posts[id]
+		if !ok || post.Status != repo.PostStatusPublished {
+			continue
+		}
+		result.Post = append(result.Post, post)
+		authorIDs = append(authorIDs, post.UserId)
+	}
+	result.Count = len(result.Post)
+
+	authors, err := h.storage.User().GetUserProfiles(ctx, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	bookmarked := make(map[int]bool)
+	if viewerID != 0 {
+		bookmarked, err = h.storage.ReadingList().GetBookmarked(ctx, viewerID, ids)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, post := range result.Post {
+		if author, ok := authors[post.UserId]; ok {
+			post.User = repo.UserProfile{
+				Id:              author.Id,
+				FirstName:       author.FirstName,
+				LastName:        author.LastName,
+				Email:           author.Email,
+				ProfileImageUrl: author.ProfileImageUrl,
+			}
+		}
+		post.Bookmarked = bookmarked[post.Id]
+	}
+
+	return result, nil
+}
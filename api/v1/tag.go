@@ -0,0 +1,152 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/post/api/models"
+	"github.com/post/pkg/utils"
+	"github.com/post/storage/repo"
+)
+
+// maxTagLength is the size of the name and slug columns of tags
+const maxTagLength = 64
+
+// validateTags checks that the names and the slugs made of them fit into
+// the tags table. A slug can be longer than its name, as transliteration
+// turns some letters into two, and is empty if no letter has a slug form
+func validateTags(names []string) error {
+	for _, name := range names {
+		slug := utils.Slugify(name)
+		if utf8.RuneCountInString(name) > maxTagLength || len(slug) > maxTagLength {
+			return ErrTagTooLong
+		}
+		if slug == "" {
+			return ErrTagWithoutSlug
+		}
+	}
+	return nil
+}
+
+// @Router /tags [get]
+// @Summary Get all tags
+// @Description Get all tags ordered by popularity
+// @Tags tag
+// @Accept json
+// @Produce json
+// @Param filter query models.GetAllTagsParams false "Filter"
+// @Success 200 {object} models.GetAllTagsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) GetAllTags(c *gin.Context) {
+	req, err := tagsParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := h.storage.Tag().GetAll(c.Request.Context(), repo.GetTagQuery{
+		Page:   req.Page,
+		Limit:  req.Limit,
+		Search: req.Search,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, tagsResponse(result))
+}
+
+// @Router /tags/{slug}/posts [get]
+// @Summary Get posts by tag
+// @Description Get posts by tag
+// @Tags tag
+// @Accept json
+// @Produce json
+// @Param slug path string true "Slug"
+// @Param filter query models.GetAllPostsParams false "Filter"
+// @Success 200 {object} models.GetAllPostsResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+func (h *handlerV1) GetTagPosts(c *gin.Context) {
+	tag, err := h.storage.Tag().GetBySlug(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	req, err := postsParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := h.storage.Post().GetAll(c.Request.Context(), repo.GetPostQuery{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		CategoryID: req.CategoryId,
+		UserID:     req.UserID,
+		Search:     req.Search,
+		Tag:        tag.Slug,
+		Statuses:   []string{repo.PostStatusPublished},
+		Sort:       req.Sort,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, postsResponse(result))
+}
+
+func tagsParams(c *gin.Context) (*models.GetAllTagsParams, error) {
+	limit, err := limitParam(c, maxListLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := pageParam(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GetAllTagsParams{
+		Page:   page,
+		Limit:  limit,
+		Search: c.Query("search"),
+	}, nil
+}
+
+func tagsResponse(data *repo.GetAllTagsResult) *models.GetAllTagsResponse {
+	response := models.GetAllTagsResponse{
+		Tags:  make([]*models.Tag, 0),
+		Count: data.Count,
+	}
+
+	for _, tag := range data.Tags {
+		t := parseTagModel(tag)
+		response.Tags = append(response.Tags, &t)
+	}
+
+	return &response
+}
+
+func parseTagModel(tag *repo.Tag) models.Tag {
+	return models.Tag{
+		Id:         tag.Id,
+		Name:       tag.Name,
+		Slug:       tag.Slug,
+		PostsCount: tag.PostsCount,
+		CreatedAt:  tag.CreatedAt,
+	}
+}
@@ -0,0 +1,21 @@
+package v1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTags(t *testing.T) {
+	require.NoError(t, validateTags(nil))
+	require.NoError(t, validateTags([]string{"go", strings.Repeat("a", maxTagLength)}))
+	require.NoError(t, validateTags([]string{strings.Repeat("ж", maxTagLength)}))
+
+	require.ErrorIs(t, validateTags([]string{"go", strings.Repeat("a", maxTagLength+1)}), ErrTagTooLong)
+	// the name fits, but its transliterated slug does not
+	require.ErrorIs(t, validateTags([]string{strings.Repeat("ш", maxTagLength)}), ErrTagTooLong)
+
+	require.ErrorIs(t, validateTags([]string{"go", "日本"}), ErrTagWithoutSlug)
+	require.ErrorIs(t, validateTags([]string{"--"}), ErrTagWithoutSlug)
+}
@@ -3,6 +3,7 @@ package v1
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/post/api/models"
@@ -28,7 +29,7 @@ func (h *handlerV1) GetUser(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.storage.User().Get(id)
+	resp, err := h.storage.User().Get(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: err.Error(),
@@ -81,7 +82,7 @@ func (h *handlerV1) CreateUser(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.storage.User().Create(&repo.User{
+	resp, err := h.storage.User().Create(c.Request.Context(), &repo.User{
 		FirstName:       req.FirstName,
 		LastName:        req.LastName,
 		PhoneNumber:     req.PhoneNumber,
@@ -116,6 +117,8 @@ func (h *handlerV1) CreateUser(c *gin.Context) {
 // @Router /users [get]
 // @Summary Get all Users
 // @Description Get all Users
+// @Description Pass an empty cursor to page by cursor, then next_cursor of the response as cursor.
+// @Description The count is only computed in page mode, unless with_count is set
 // @Tags users
 // @Accept json
 // @Produce json
@@ -130,11 +133,20 @@ func (h *handlerV1) GetAllUsers(c *gin.Context) {
 		})
 		return
 	}
-	result, err := h.storage.User().GetAll(repo.GetUserQuery{
-		Page:       req.Page,
-		Limit:      req.Limit,
+	cursor, err := cursorParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	page, limit := cursor.page(req.Page, req.Limit)
+	result, err := h.storage.User().GetAll(c.Request.Context(), repo.GetUserQuery{
+		Page:       page,
+		Limit:      limit,
 		Search:     req.Search,
 		SortByDate: req.SortByDate,
+		After:      cursor.after,
+		SkipCount:  !cursor.withCount,
 	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -143,29 +155,32 @@ func (h *handlerV1) GetAllUsers(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, usersResponse(result))
+	var next string
+	result.Users, next = nextPage(cursor, result.Users, req.Limit, func(u *repo.User) (time.Time, int) {
+		return u.CreatedAt, u.Id
+	})
+
+	response := usersResponse(result)
+	response.NextCursor = next
+	c.JSON(http.StatusOK, response)
 }
 
 func usersParams(c *gin.Context) (*models.GetAllUsersParams, error) {
 	var (
-		limit      int = 10
-		page       int = 1
+		limit      int
+		page       int
 		sortByDate string
 		err        error
 	)
 
-	if c.Query("limit") != "" {
-		limit, err = strconv.Atoi(c.Query("limit"))
-		if err != nil {
-			return nil, err
-		}
+	limit, err = limitParam(c, maxListLimit)
+	if err != nil {
+		return nil, err
 	}
 
-	if c.Query("page") != "" {
-		page, err = strconv.Atoi(c.Query("page"))
-		if err != nil {
-			return nil, err
-		}
+	page, err = pageParam(c)
+	if err != nil {
+		return nil, err
 	}
 
 	if c.Query("sort_by_date") != "" &&
@@ -250,7 +265,7 @@ func (h *handlerV1) UpdateUser(ctx *gin.Context) {
 	}
 
 	req.Id = id
-	user, err := h.storage.User().Update(&repo.User{
+	user, err := h.storage.User().Update(ctx.Request.Context(), &repo.User{
 		Id:              req.Id,
 		FirstName:       req.FirstName,
 		LastName:        req.LastName,
@@ -290,13 +305,18 @@ func (h *handlerV1) DeleteUser(ctx *gin.Context) {
 		return
 	}
 
-	err = h.storage.User().Delete(id)
+	err = h.storage.User().Delete(ctx.Request.Context(), id)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: err.Error(),
 		})
 		return
 	}
+	// the posts of the user are deleted with it
+	if err := h.related.Invalidate(ctx.Request.Context()); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"message": "successful delete method",
 	})
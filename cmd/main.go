@@ -1,15 +1,26 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/go-redis/redis/v9"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 	"github.com/post/api"
 	"github.com/post/config"
+	"github.com/post/pkg/claps"
+	"github.com/post/pkg/scheduler"
 	"github.com/post/pkg/utils"
+	"github.com/post/pkg/views"
 	"github.com/post/storage"
 )
 
@@ -35,6 +46,9 @@ func main() {
 
 	strg := storage.NewStoragePg(psqlConn)
 	inMemory := storage.NewInMemoryStorage(rdb)
+	if cfg.Cache.Enabled {
+		strg = storage.NewCachedStorage(strg, inMemory, cfg.Cache)
+	}
 
 	apiServer := api.New(&api.RouterOptions{
 		Cfg:      &cfg,
@@ -44,10 +58,45 @@ func main() {
 
 	utils.GetSecretKey(cfg.SecretKey)
 
-	err = apiServer.Run(cfg.HttpPort)
-	if err != nil {
-		log.Fatalf("failed to run server: %v", err)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		scheduler.New(&cfg, strg, inMemory).Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		claps.NewBuffer(strg, inMemory).Run(ctx, cfg.Claps.FlushInterval)
+	}()
+	go func() {
+		defer wg.Done()
+		views.NewCounter(strg, inMemory, cfg.Views.Window).Run(ctx, cfg.Views.FlushInterval)
+	}()
+
+	srv := &http.Server{
+		Addr:    cfg.HttpPort,
+		Handler: apiServer,
+	}
+
+	go func() {
+		err := srv.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("failed to run server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("failed to shutdown server: %v", err)
 	}
+	wg.Wait()
 
 	log.Print("Server stopped")
 }
@@ -0,0 +1,41 @@
+// Command recount rebuilds the denormalized counters of posts
+// (likes, dislikes, comments and bookmarks) from the source tables.
+//
+//	go run ./cmd/recount
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/post/config"
+	"github.com/post/storage"
+)
+
+func main() {
+	cfg := config.Load("./")
+
+	psqlUrl := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		cfg.PostConfig.Host,
+		cfg.PostConfig.Port,
+		cfg.PostConfig.User,
+		cfg.PostConfig.Password,
+		cfg.PostConfig.Database,
+	)
+
+	psqlConn, err := sqlx.Connect("postgres", psqlUrl)
+	if err != nil {
+		log.Fatalf("failed to connect database: %v", err)
+	}
+	defer psqlConn.Close()
+
+	fixed, err := storage.NewStoragePg(psqlConn).Post().RecountCounters(context.Background())
+	if err != nil {
+		log.Fatalf("failed to recount: %v", err)
+	}
+
+	log.Printf("recount: fixed counters of %d posts", fixed)
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 	"github.com/subosito/gotenv"
 )
@@ -11,6 +13,10 @@ type Config struct {
 	HttpPort    string
 	SMTP        Smtp
 	SecretKey   string
+	Scheduler   SchedulerConfig
+	Claps       ClapsConfig
+	Views       ViewsConfig
+	Cache       CacheConfig
 }
 
 type PostgresConfig struct {
@@ -19,6 +25,8 @@ type PostgresConfig struct {
 	User     string
 	Database string
 	Password string
+	// RequestTimeout bounds the storage calls of one API request
+	RequestTimeout time.Duration
 }
 
 type RedisConfig struct {
@@ -26,6 +34,28 @@ type RedisConfig struct {
 	RedisPort string
 }
 
+type SchedulerConfig struct {
+	Interval         time.Duration
+	BatchSize        int
+	RankingsInterval time.Duration
+}
+
+type ClapsConfig struct {
+	FlushInterval time.Duration
+}
+
+type ViewsConfig struct {
+	Window        time.Duration
+	FlushInterval time.Duration
+}
+
+type CacheConfig struct {
+	Enabled     bool
+	PostTTL     time.Duration
+	ProfileTTL  time.Duration
+	CategoryTTL time.Duration
+}
+
 type Smtp struct {
 	Sender   string
 	Password string
@@ -35,6 +65,17 @@ func Load(path string) Config {
 	gotenv.Load(path + "/.env")
 	Conf := viper.New()
 	Conf.AutomaticEnv()
+	Conf.SetDefault("POSTGRES_REQUEST_TIMEOUT", "5s")
+	Conf.SetDefault("SCHEDULER_INTERVAL", "30s")
+	Conf.SetDefault("SCHEDULER_BATCH_SIZE", 100)
+	Conf.SetDefault("SCHEDULER_RANKINGS_INTERVAL", "5m")
+	Conf.SetDefault("CLAPS_FLUSH_INTERVAL", "5s")
+	Conf.SetDefault("VIEWS_WINDOW", "30m")
+	Conf.SetDefault("VIEWS_FLUSH_INTERVAL", "10s")
+	Conf.SetDefault("CACHE_ENABLED", false)
+	Conf.SetDefault("CACHE_POST_TTL", "5m")
+	Conf.SetDefault("CACHE_PROFILE_TTL", "30m")
+	Conf.SetDefault("CACHE_CATEGORY_TTL", "1h")
 	cfg := Config{
 		HttpPort: Conf.GetString("HTTP_PORT"),
 		PostConfig: PostgresConfig{
@@ -43,6 +84,8 @@ func Load(path string) Config {
 			User:     Conf.GetString("POSTGRES_USER"),
 			Database: Conf.GetString("POSTGRES_DATABASE"),
 			Password: Conf.GetString("POSTGRES_PASSWORD"),
+
+			RequestTimeout: Conf.GetDuration("POSTGRES_REQUEST_TIMEOUT"),
 		},
 		RedisConfig: RedisConfig{
 			RedisHost: Conf.GetString("REDIS_HOST"),
@@ -53,6 +96,24 @@ func Load(path string) Config {
 			Password: Conf.GetString("SMTP_PASSWORD"),
 		},
 		SecretKey: Conf.GetString("SECRET_KEY"),
+		Scheduler: SchedulerConfig{
+			Interval:         Conf.GetDuration("SCHEDULER_INTERVAL"),
+			BatchSize:        Conf.GetInt("SCHEDULER_BATCH_SIZE"),
+			RankingsInterval: Conf.GetDuration("SCHEDULER_RANKINGS_INTERVAL"),
+		},
+		Claps: ClapsConfig{
+			FlushInterval: Conf.GetDuration("CLAPS_FLUSH_INTERVAL"),
+		},
+		Views: ViewsConfig{
+			Window:        Conf.GetDuration("VIEWS_WINDOW"),
+			FlushInterval: Conf.GetDuration("VIEWS_FLUSH_INTERVAL"),
+		},
+		Cache: CacheConfig{
+			Enabled:     Conf.GetBool("CACHE_ENABLED"),
+			PostTTL:     Conf.GetDuration("CACHE_POST_TTL"),
+			ProfileTTL:  Conf.GetDuration("CACHE_PROFILE_TTL"),
+			CategoryTTL: Conf.GetDuration("CACHE_CATEGORY_TTL"),
+		},
 	}
 	return cfg
 }
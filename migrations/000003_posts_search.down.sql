@@ -0,0 +1,3 @@
+drop index if exists posts_search_vector_idx;
+
+alter table posts drop column if exists search_vector;
@@ -0,0 +1,7 @@
+ALTER TABLE "posts" ADD COLUMN if not exists "search_vector" tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce("title", '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce("description", '')), 'B')
+    ) STORED;
+
+CREATE INDEX if not exists "posts_search_vector_idx" ON "posts" USING GIN("search_vector");
@@ -0,0 +1,14 @@
+CREATE TABLE if not exists "tags"(
+    "id" serial PRIMARY KEY,
+    "name" VARCHAR(64) NOT NULL,
+    "slug" VARCHAR(64) NOT NULL UNIQUE,
+    "created_at" TIMESTAMP WITH TIME ZONE default current_timestamp
+);
+
+CREATE TABLE if not exists "post_tags"(
+    "post_id" INTEGER NOT NULL REFERENCES posts(id)ON DELETE CASCADE,
+    "tag_id" INTEGER NOT NULL REFERENCES tags(id)ON DELETE CASCADE,
+    PRIMARY KEY(post_id, tag_id)
+);
+
+CREATE INDEX if not exists "post_tags_tag_id_idx" ON "post_tags"("tag_id");
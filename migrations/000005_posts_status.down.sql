@@ -0,0 +1,5 @@
+drop index if exists posts_status_created_at_idx;
+
+alter table posts drop column if exists published_at;
+
+alter table posts drop column if exists status;
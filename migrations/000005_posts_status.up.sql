@@ -0,0 +1,8 @@
+ALTER TABLE "posts" ADD COLUMN if not exists "status" VARCHAR(16)
+    CHECK("status" IN('draft','published','unlisted','archived')) NOT NULL default 'draft';
+
+ALTER TABLE "posts" ADD COLUMN if not exists "published_at" TIMESTAMP WITH TIME ZONE;
+
+UPDATE "posts" SET "status"='published', "published_at"="created_at";
+
+CREATE INDEX if not exists "posts_status_created_at_idx" ON "posts"("status", "created_at");
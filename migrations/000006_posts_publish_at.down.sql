@@ -0,0 +1,9 @@
+drop index if exists posts_scheduled_publish_at_idx;
+
+update posts set status='draft' where status='scheduled';
+
+alter table posts drop constraint if exists posts_status_check;
+alter table posts add constraint posts_status_check
+    check(status in('draft','published','unlisted','archived'));
+
+alter table posts drop column if exists publish_at;
@@ -0,0 +1,8 @@
+ALTER TABLE "posts" ADD COLUMN if not exists "publish_at" TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE "posts" DROP CONSTRAINT if exists "posts_status_check";
+ALTER TABLE "posts" ADD CONSTRAINT "posts_status_check"
+    CHECK("status" IN('draft','scheduled','published','unlisted','archived'));
+
+CREATE INDEX if not exists "posts_scheduled_publish_at_idx" ON "posts"("publish_at")
+    WHERE "status"='scheduled';
@@ -0,0 +1,16 @@
+CREATE TABLE if not exists "post_revisions"(
+    "id" serial PRIMARY KEY,
+    "post_id" INTEGER NOT NULL REFERENCES posts(id)ON DELETE CASCADE,
+    "revision" INTEGER NOT NULL,
+    "editor_id" INTEGER REFERENCES users(id)ON DELETE SET NULL,
+    "title" VARCHAR(255) NOT NULL,
+    "description" TEXT,
+    "image_url" VARCHAR(255),
+    "category_id" INTEGER NOT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(post_id, revision)
+);
+
+INSERT INTO "post_revisions"("post_id", "revision", "editor_id", "title", "description", "image_url", "category_id", "created_at")
+SELECT "id", 1, "user_id", "title", "description", "image_url", "category_id", coalesce("updated_at", "created_at")
+FROM "posts";
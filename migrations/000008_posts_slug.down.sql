@@ -0,0 +1,5 @@
+drop table if exists post_slugs;
+
+alter table posts drop constraint if exists posts_user_id_slug_key;
+
+alter table posts drop column if exists slug;
@@ -0,0 +1,15 @@
+ALTER TABLE "posts" ADD COLUMN if not exists "slug" VARCHAR(255);
+
+UPDATE "posts" SET "slug"=trim(both '-' from lower(regexp_replace("title" || ' ' || "id", '[^a-zA-Z0-9]+', '-', 'g')));
+
+ALTER TABLE "posts" ALTER COLUMN "slug" SET NOT NULL;
+ALTER TABLE "posts" ADD CONSTRAINT "posts_user_id_slug_key" UNIQUE("user_id", "slug");
+
+CREATE TABLE if not exists "post_slugs"(
+    "id" serial PRIMARY KEY,
+    "post_id" INTEGER NOT NULL REFERENCES posts(id)ON DELETE CASCADE,
+    "user_id" INTEGER NOT NULL REFERENCES users(id)ON DELETE CASCADE,
+    "slug" VARCHAR(255) NOT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, slug)
+);
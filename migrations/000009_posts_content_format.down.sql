@@ -0,0 +1,5 @@
+alter table post_revisions drop column if exists content_format;
+
+alter table posts drop column if exists description_html;
+
+alter table posts drop column if exists content_format;
@@ -0,0 +1,6 @@
+ALTER TABLE "posts" ADD COLUMN if not exists "content_format" VARCHAR(16)
+    CHECK("content_format" IN('plain','markdown')) NOT NULL default 'plain';
+
+ALTER TABLE "posts" ADD COLUMN if not exists "description_html" TEXT;
+
+ALTER TABLE "post_revisions" ADD COLUMN if not exists "content_format" VARCHAR(16) NOT NULL default 'plain';
@@ -0,0 +1,5 @@
+alter table posts drop column if exists table_of_contents;
+
+alter table posts drop column if exists excerpt;
+
+alter table posts drop column if exists reading_time_minutes;
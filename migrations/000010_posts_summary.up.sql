@@ -0,0 +1,9 @@
+ALTER TABLE "posts" ADD COLUMN if not exists "reading_time_minutes" INTEGER NOT NULL default 0;
+
+ALTER TABLE "posts" ADD COLUMN if not exists "excerpt" TEXT NOT NULL default '';
+
+ALTER TABLE "posts" ADD COLUMN if not exists "table_of_contents" JSONB NOT NULL default '[]';
+
+UPDATE "posts" SET
+    "reading_time_minutes"=ceil(coalesce(array_length(regexp_split_to_array(nullif(trim("description"), ''), '\s+'), 1), 0) / 200.0),
+    "excerpt"=left(trim(regexp_replace(coalesce("description", ''), '\s+', ' ', 'g')), 200);
@@ -0,0 +1,3 @@
+drop table if exists reading_list_items;
+
+drop table if exists reading_lists;
@@ -0,0 +1,27 @@
+CREATE TABLE if not exists "reading_lists"(
+    "id" serial PRIMARY KEY,
+    "user_id" INTEGER NOT NULL REFERENCES users(id)ON DELETE CASCADE,
+    "name" VARCHAR(100) NOT NULL,
+    "description" TEXT,
+    "visibility" VARCHAR(16) CHECK("visibility" IN('public','private')) NOT NULL default 'private',
+    "is_default" BOOLEAN NOT NULL default false,
+    "created_at" TIMESTAMP WITH TIME ZONE default current_timestamp,
+    "updated_at" TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX if not exists "reading_lists_user_id_idx" ON "reading_lists"("user_id");
+
+CREATE UNIQUE INDEX if not exists "reading_lists_default_idx" ON "reading_lists"("user_id") WHERE "is_default";
+
+CREATE TABLE if not exists "reading_list_items"(
+    "list_id" INTEGER NOT NULL REFERENCES reading_lists(id)ON DELETE CASCADE,
+    "post_id" INTEGER NOT NULL REFERENCES posts(id)ON DELETE CASCADE,
+    "created_at" TIMESTAMP WITH TIME ZONE default current_timestamp,
+    PRIMARY KEY(list_id, post_id)
+);
+
+CREATE INDEX if not exists "reading_list_items_post_id_idx" ON "reading_list_items"("post_id");
+
+INSERT INTO "reading_lists"("user_id", "name", "is_default")
+SELECT "id", 'Saved', true FROM "users"
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,5 @@
+drop index if exists posts_created_at_id_idx;
+
+drop table if exists category_follows;
+
+drop table if exists follows;
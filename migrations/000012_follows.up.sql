@@ -0,0 +1,18 @@
+CREATE TABLE if not exists "follows"(
+    "follower_id" INTEGER NOT NULL REFERENCES users(id)ON DELETE CASCADE,
+    "followee_id" INTEGER NOT NULL REFERENCES users(id)ON DELETE CASCADE,
+    "created_at" TIMESTAMP WITH TIME ZONE default current_timestamp,
+    PRIMARY KEY(follower_id, followee_id),
+    CHECK("follower_id" <> "followee_id")
+);
+
+CREATE INDEX if not exists "follows_followee_id_idx" ON "follows"("followee_id");
+
+CREATE TABLE if not exists "category_follows"(
+    "user_id" INTEGER NOT NULL REFERENCES users(id)ON DELETE CASCADE,
+    "category_id" INTEGER NOT NULL REFERENCES categories(id)ON DELETE CASCADE,
+    "created_at" TIMESTAMP WITH TIME ZONE default current_timestamp,
+    PRIMARY KEY(user_id, category_id)
+);
+
+CREATE INDEX if not exists "posts_created_at_id_idx" ON "posts"("created_at" DESC, "id" DESC);
@@ -0,0 +1,5 @@
+drop index if exists comments_parent_id_idx;
+
+alter table comments drop column if exists deleted_at;
+
+alter table comments drop column if exists parent_id;
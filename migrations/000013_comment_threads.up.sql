@@ -0,0 +1,5 @@
+ALTER TABLE "comments" ADD COLUMN if not exists "parent_id" INTEGER REFERENCES comments(id)ON DELETE CASCADE;
+
+ALTER TABLE "comments" ADD COLUMN if not exists "deleted_at" TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX if not exists "comments_parent_id_idx" ON "comments"("parent_id");
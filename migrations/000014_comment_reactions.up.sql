@@ -0,0 +1,7 @@
+CREATE TABLE if not exists "comment_reactions"(
+    "comment_id" INTEGER NOT NULL REFERENCES comments(id)ON DELETE CASCADE,
+    "user_id" INTEGER NOT NULL REFERENCES users(id)ON DELETE CASCADE,
+    "reaction" VARCHAR(16) NOT NULL CHECK ("reaction" IN ('like', 'love', 'laugh', 'wow', 'sad', 'angry')),
+    "created_at" TIMESTAMP WITH TIME ZONE default current_timestamp,
+    PRIMARY KEY(comment_id, user_id)
+);
@@ -0,0 +1,8 @@
+CREATE TABLE if not exists "claps"(
+    "post_id" INTEGER NOT NULL REFERENCES posts(id)ON DELETE CASCADE,
+    "user_id" INTEGER NOT NULL REFERENCES users(id)ON DELETE CASCADE,
+    "count" INTEGER NOT NULL CHECK ("count" BETWEEN 1 AND 50),
+    "created_at" TIMESTAMP WITH TIME ZONE default current_timestamp,
+    "updated_at" TIMESTAMP WITH TIME ZONE default current_timestamp,
+    PRIMARY KEY(post_id, user_id)
+);
@@ -0,0 +1,7 @@
+alter table posts drop column if exists bookmarks_count;
+
+alter table posts drop column if exists comments_count;
+
+alter table posts drop column if exists dislikes_count;
+
+alter table posts drop column if exists likes_count;
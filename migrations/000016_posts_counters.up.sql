@@ -0,0 +1,13 @@
+ALTER TABLE "posts" ADD COLUMN if not exists "likes_count" INTEGER NOT NULL default 0;
+
+ALTER TABLE "posts" ADD COLUMN if not exists "dislikes_count" INTEGER NOT NULL default 0;
+
+ALTER TABLE "posts" ADD COLUMN if not exists "comments_count" INTEGER NOT NULL default 0;
+
+ALTER TABLE "posts" ADD COLUMN if not exists "bookmarks_count" INTEGER NOT NULL default 0;
+
+UPDATE "posts" SET
+    "likes_count"=(SELECT count(1) FROM "likes" l WHERE l."post_id"="posts"."id" AND l."status"),
+    "dislikes_count"=(SELECT count(1) FROM "likes" l WHERE l."post_id"="posts"."id" AND NOT l."status"),
+    "comments_count"=(SELECT count(1) FROM "comments" c WHERE c."post_id"="posts"."id" AND c."deleted_at" IS NULL),
+    "bookmarks_count"=(SELECT count(1) FROM "reading_list_items" i WHERE i."post_id"="posts"."id");
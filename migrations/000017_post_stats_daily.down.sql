@@ -0,0 +1,3 @@
+drop index if exists post_stats_daily_day_idx;
+
+drop table if exists post_stats_daily;
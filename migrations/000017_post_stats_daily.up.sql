@@ -0,0 +1,13 @@
+CREATE TABLE if not exists "post_stats_daily"(
+    "post_id" INTEGER NOT NULL REFERENCES posts(id)ON DELETE CASCADE,
+    "day" DATE NOT NULL,
+    "views" INTEGER NOT NULL default 0,
+    "unique_readers" INTEGER NOT NULL default 0,
+    "reads" INTEGER NOT NULL default 0,
+    "likes" INTEGER NOT NULL default 0,
+    "comments" INTEGER NOT NULL default 0,
+    "referrers" JSONB NOT NULL default '{}',
+    PRIMARY KEY(post_id, day)
+);
+
+CREATE INDEX if not exists "post_stats_daily_day_idx" ON "post_stats_daily"("day");
@@ -0,0 +1,3 @@
+drop index if exists posts_views_count_idx;
+
+drop materialized view if exists post_rankings;
@@ -0,0 +1,16 @@
+CREATE MATERIALIZED VIEW if not exists "post_rankings" AS
+    SELECT
+        s.post_id,
+        sum((s.views + 3*s.likes + 5*s.comments) * power(0.5, (now() AT TIME ZONE 'UTC')::date - s.day)) AS trending_score,
+        coalesce(sum(s.views + 3*s.likes + 5*s.comments) FILTER (WHERE s.day > (now() AT TIME ZONE 'UTC')::date - 7), 0) AS week_score,
+        sum(s.views + 3*s.likes + 5*s.comments) AS month_score
+    FROM post_stats_daily s
+    WHERE s.day > (now() AT TIME ZONE 'UTC')::date - 30
+    GROUP BY s.post_id;
+
+CREATE UNIQUE INDEX if not exists "post_rankings_post_id_idx" ON "post_rankings"("post_id");
+CREATE INDEX if not exists "post_rankings_trending_idx" ON "post_rankings"("trending_score" DESC, "post_id" DESC);
+CREATE INDEX if not exists "post_rankings_week_idx" ON "post_rankings"("week_score" DESC, "post_id" DESC);
+CREATE INDEX if not exists "post_rankings_month_idx" ON "post_rankings"("month_score" DESC, "post_id" DESC);
+
+CREATE INDEX if not exists "posts_views_count_idx" ON "posts"("views_count" DESC, "id" DESC);
@@ -0,0 +1,203 @@
+package claps
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/post/storage"
+	"github.com/post/storage/repo"
+)
+
+const (
+	pendingKey   = "claps_pending"
+	flushingKey  = "claps_flushing"
+	flushLockKey = "claps_flush_lock"
+
+	defaultFlushInterval = 5 * time.Second
+)
+
+// Buffer collects claps in Redis and writes them to Postgres in batches,
+// so a burst of clap requests costs a single upsert per user and post
+type Buffer struct {
+	storage  storage.StorageI
+	inMemory storage.InMemoryStorageI
+	owner    string
+}
+
+func NewBuffer(strg storage.StorageI, inMemory storage.InMemoryStorageI) *Buffer {
+	host, _ := os.Hostname()
+
+	return &Buffer{
+		storage:  strg,
+		inMemory: inMemory,
+		owner:    host + "_" + strconv.Itoa(os.Getpid()),
+	}
+}
+
+// Add buffers up to delta claps of the user for the post without going over
+// repo.MaxClapsPerUser. It returns the user's clap count including the new claps
+func (b *Buffer) Add(ctx context.Context, postID, userID, delta int) (int, error) {
+	stored, err := b.storage.Clap().Get(ctx, postID, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	flushing, err := b.pending(ctx, flushingKey, postID, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	f := field(postID, userID)
+	pending, err := b.inMemory.HIncrBy(ctx, pendingKey, f, delta)
+	if err != nil {
+		return 0, err
+	}
+
+	// Take back what went over the limit. Postgres caps the count
+	// as well, so a flush running concurrently can not exceed it
+	if over := stored + flushing + pending - repo.MaxClapsPerUser; over > 0 {
+		if over > delta {
+			over = delta
+		}
+		pending, err = b.inMemory.HIncrBy(ctx, pendingKey, f, -over)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return min(stored+flushing+pending, repo.MaxClapsPerUser), nil
+}
+
+// Count returns the claps of the user for the post, flushed or not
+func (b *Buffer) Count(ctx context.Context, postID, userID int) (int, error) {
+	stored, err := b.storage.Clap().Get(ctx, postID, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	count := stored
+	for _, key := range []string{flushingKey, pendingKey} {
+		n, err := b.pending(ctx, key, postID, userID)
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+
+	return min(count, repo.MaxClapsPerUser), nil
+}
+
+func (b *Buffer) pending(ctx context.Context, key string, postID, userID int) (int, error) {
+	val, err := b.inMemory.HGet(ctx, key, field(postID, userID))
+	if errors.Is(err, storage.ErrKeyNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(val)
+}
+
+// Run flushes the buffered claps every interval until ctx is cancelled
+func (b *Buffer) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultFlushInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Only one replica flushes at a time. The lock is held for the
+		// whole flush, so no other replica applies the same batch
+		if _, err := storage.WithLock(ctx, b.inMemory, flushLockKey, b.owner, interval, b.Flush); err != nil {
+			log.Printf("claps: failed to flush: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush writes the buffered claps to Postgres. New claps keep going to a
+// fresh buffer meanwhile. A batch left over by a failed flush is retried
+// before the current one
+func (b *Buffer) Flush(ctx context.Context) error {
+	leftover, err := b.inMemory.HGetAll(ctx, flushingKey)
+	if err != nil {
+		return err
+	}
+	if len(leftover) == 0 {
+		ok, err := b.inMemory.Rename(ctx, pendingKey, flushingKey)
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	values, err := b.inMemory.HGetAll(ctx, flushingKey)
+	if err != nil {
+		return err
+	}
+
+	claps := make([]*repo.Clap, 0, len(values))
+	for f, val := range values {
+		postID, userID, err := parseField(f)
+		if err != nil {
+			log.Printf("claps: skipping %q: %v", f, err)
+			continue
+		}
+		count, err := strconv.Atoi(val)
+		if err != nil {
+			log.Printf("claps: skipping %q: %v", f, err)
+			continue
+		}
+		claps = append(claps, &repo.Clap{
+			PostID: postID,
+			UserID: userID,
+			Count:  count,
+		})
+	}
+
+	if err := b.storage.Clap().AddClaps(ctx, claps); err != nil {
+		return err
+	}
+
+	return b.inMemory.Delete(ctx, flushingKey)
+}
+
+func field(postID, userID int) string {
+	return strconv.Itoa(postID) + ":" + strconv.Itoa(userID)
+}
+
+func parseField(f string) (int, int, error) {
+	post, user, ok := strings.Cut(f, ":")
+	if !ok {
+		return 0, 0, errors.New("invalid field")
+	}
+
+	postID, err := strconv.Atoi(post)
+	if err != nil {
+		return 0, 0, err
+	}
+	userID, err := strconv.Atoi(user)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return postID, userID, nil
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
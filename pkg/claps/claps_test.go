@@ -0,0 +1,144 @@
+package claps
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/post/storage"
+	"github.com/post/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeInMemory struct {
+	storage.InMemoryStorageI
+	hashes map[string]map[string]string
+}
+
+func (f *fakeInMemory) HIncrBy(ctx context.Context, key, field string, n int) (int, error) {
+	if f.hashes[key] == nil {
+		f.hashes[key] = make(map[string]string)
+	}
+	val, _ := strconv.Atoi(f.hashes[key][field])
+	val += n
+	f.hashes[key][field] = strconv.Itoa(val)
+	return val, nil
+}
+
+func (f *fakeInMemory) HGet(ctx context.Context, key, field string) (string, error) {
+	val, ok := f.hashes[key][field]
+	if !ok {
+		return "", storage.ErrKeyNotFound
+	}
+	return val, nil
+}
+
+func (f *fakeInMemory) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	result := make(map[string]string)
+	for k, v := range f.hashes[key] {
+		result[k] = v
+	}
+	return result, nil
+}
+
+func (f *fakeInMemory) Rename(ctx context.Context, key, newKey string) (bool, error) {
+	if _, ok := f.hashes[key]; !ok {
+		return false, nil
+	}
+	f.hashes[newKey] = f.hashes[key]
+	delete(f.hashes, key)
+	return true, nil
+}
+
+func (f *fakeInMemory) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(f.hashes, key)
+	}
+	return nil
+}
+
+func (f *fakeInMemory) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
+type fakeClaps struct {
+	repo.ClapStorageI
+	counts map[string]int
+}
+
+func (f *fakeClaps) Get(ctx context.Context, postID, userID int) (int, error) {
+	return f.counts[field(postID, userID)], nil
+}
+
+func (f *fakeClaps) AddClaps(ctx context.Context, claps []*repo.Clap) error {
+	for _, c := range claps {
+		f.counts[field(c.PostID, c.UserID)] = min(f.counts[field(c.PostID, c.UserID)]+c.Count, repo.MaxClapsPerUser)
+	}
+	return nil
+}
+
+type fakeStorage struct {
+	storage.StorageI
+	claps *fakeClaps
+}
+
+func (f *fakeStorage) Clap() repo.ClapStorageI {
+	return f.claps
+}
+
+func newTestBuffer() (*Buffer, *fakeClaps) {
+	claps := &fakeClaps{counts: make(map[string]int)}
+	inMemory := &fakeInMemory{hashes: make(map[string]map[string]string)}
+	return NewBuffer(&fakeStorage{claps: claps}, inMemory), claps
+}
+
+func TestBufferAdd(t *testing.T) {
+	ctx := context.Background()
+	b, claps := newTestBuffer()
+
+	count, err := b.Add(ctx, 1, 2, 10)
+	require.NoError(t, err)
+	require.Equal(t, 10, count)
+	require.Empty(t, claps.counts)
+
+	count, err = b.Add(ctx, 1, 2, 45)
+	require.NoError(t, err)
+	require.Equal(t, repo.MaxClapsPerUser, count)
+
+	count, err = b.Count(ctx, 1, 2)
+	require.NoError(t, err)
+	require.Equal(t, repo.MaxClapsPerUser, count)
+
+	require.NoError(t, b.Flush(ctx))
+	require.Equal(t, repo.MaxClapsPerUser, claps.counts[field(1, 2)])
+
+	count, err = b.Add(ctx, 1, 2, 1)
+	require.NoError(t, err)
+	require.Equal(t, repo.MaxClapsPerUser, count)
+
+	require.NoError(t, b.Flush(ctx))
+	require.Equal(t, repo.MaxClapsPerUser, claps.counts[field(1, 2)])
+}
+
+func TestBufferFlushRetriesLeftover(t *testing.T) {
+	ctx := context.Background()
+	b, claps := newTestBuffer()
+	inMemory := b.inMemory.(*fakeInMemory)
+
+	_, err := b.Add(ctx, 1, 2, 3)
+	require.NoError(t, err)
+
+	// a flush that died after taking the batch
+	_, err = inMemory.Rename(ctx, pendingKey, flushingKey)
+	require.NoError(t, err)
+
+	_, err = b.Add(ctx, 1, 3, 5)
+	require.NoError(t, err)
+
+	require.NoError(t, b.Flush(ctx))
+	require.Equal(t, map[string]int{field(1, 2): 3}, claps.counts)
+
+	require.NoError(t, b.Flush(ctx))
+	require.Equal(t, map[string]int{field(1, 2): 3, field(1, 3): 5}, claps.counts)
+}
@@ -0,0 +1,115 @@
+package content
+
+import (
+	"bytes"
+	"errors"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/post/pkg/utils"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+)
+
+const (
+	FormatPlain    = "plain"
+	FormatMarkdown = "markdown"
+)
+
+var ErrUnknownFormat = errors.New("unknown content format")
+
+var (
+	markdown = goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			highlighting.NewHighlighting(
+				highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+			),
+		),
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(),
+		),
+	)
+
+	policy = newPolicy()
+)
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("id").OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	// chroma marks highlighted tokens with short class names
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^[a-zA-Z0-9 _-]+$`)).OnElements("pre", "code", "span")
+	return p
+}
+
+// Render converts the post body to sanitized HTML
+func Render(format, source string) (string, error) {
+	switch format {
+	case "", FormatPlain:
+		return renderPlain(source), nil
+	case FormatMarkdown:
+		var buf bytes.Buffer
+		ctx := parser.NewContext(parser.WithIDs(newHeadingIDs()))
+		if err := markdown.Convert([]byte(source), &buf, parser.WithContext(ctx)); err != nil {
+			return "", err
+		}
+		return policy.Sanitize(buf.String()), nil
+	}
+
+	return "", ErrUnknownFormat
+}
+
+// renderPlain escapes the text and keeps its paragraphs and line breaks
+func renderPlain(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+
+	var b strings.Builder
+	for _, paragraph := range strings.Split(source, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(paragraph), "\n", "<br>"))
+		b.WriteString("</p>\n")
+	}
+
+	return b.String()
+}
+
+// headingIDs generates heading anchors with the same transliteration as post slugs
+type headingIDs struct {
+	values map[string]bool
+}
+
+func newHeadingIDs() *headingIDs {
+	return &headingIDs{
+		values: make(map[string]bool),
+	}
+}
+
+func (s *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	base := utils.Slugify(string(value))
+	if base == "" {
+		base = "heading"
+	}
+
+	id := base
+	for i := 1; s.values[id]; i++ {
+		id = base + "-" + strconv.Itoa(i)
+	}
+	s.values[id] = true
+
+	return []byte(id)
+}
+
+func (s *headingIDs) Put(value []byte) {
+	s.values[string(value)] = true
+}
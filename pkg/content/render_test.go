@@ -0,0 +1,35 @@
+package content
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderPlain(t *testing.T) {
+	out, err := Render(FormatPlain, "Hello <b>world</b>\nline\n\nsecond")
+	require.NoError(t, err)
+	require.Equal(t, "<p>Hello &lt;b&gt;world&lt;/b&gt;<br>line</p>\n<p>second</p>\n", out)
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	source := "# Салом дунё\n\n## Intro\n\n## Intro\n\n" +
+		"<script>alert(1)</script>\n\n" +
+		"[link](javascript:alert(1)) **bold**\n\n" +
+		"```go\nfunc main() {}\n```\n"
+
+	out, err := Render(FormatMarkdown, source)
+	require.NoError(t, err)
+	require.Contains(t, out, `<h1 id="salom-dunyo">`)
+	require.Contains(t, out, `<h2 id="intro">`)
+	require.Contains(t, out, `<h2 id="intro-1">`)
+	require.Contains(t, out, "<strong>bold</strong>")
+	require.Contains(t, out, `<pre class="chroma">`)
+	require.NotContains(t, out, "<script>")
+	require.NotContains(t, out, "javascript:")
+}
+
+func TestRenderUnknownFormat(t *testing.T) {
+	_, err := Render("html", "<p>hi</p>")
+	require.ErrorIs(t, err, ErrUnknownFormat)
+}
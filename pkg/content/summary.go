@@ -0,0 +1,147 @@
+package content
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+const (
+	wordsPerMinute = 200
+	excerptLength  = 200
+)
+
+type Heading struct {
+	Level int
+	Text  string
+	Id    string
+}
+
+type Summary struct {
+	ReadingTime     int
+	Excerpt         string
+	TableOfContents []Heading
+}
+
+// Summarize computes the reading time, a plain text excerpt and the
+// table of contents of the post body. Heading ids match the ones in
+// the HTML produced by Render
+func Summarize(format, source string) (*Summary, error) {
+	switch format {
+	case "", FormatPlain:
+		return &Summary{
+			ReadingTime:     readingTime(len(strings.Fields(source))),
+			Excerpt:         excerpt([]string{source}),
+			TableOfContents: make([]Heading, 0),
+		}, nil
+	case FormatMarkdown:
+		return summarizeMarkdown([]byte(source)), nil
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+func summarizeMarkdown(source []byte) *Summary {
+	ctx := parser.NewContext(parser.WithIDs(newHeadingIDs()))
+	doc := markdown.Parser().Parse(text.NewReader(source), parser.WithContext(ctx))
+
+	var (
+		words      int
+		paragraphs []string
+		headings   = make([]Heading, 0)
+	)
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.Heading:
+			title := nodeText(node, source)
+			words += len(strings.Fields(title))
+			heading := Heading{
+				Level: node.Level,
+				Text:  title,
+			}
+			if id, ok := node.AttributeString("id"); ok {
+				heading.Id = string(id.([]byte))
+			}
+			headings = append(headings, heading)
+			return ast.WalkSkipChildren, nil
+		case *ast.Paragraph:
+			paragraph := nodeText(node, source)
+			words += len(strings.Fields(paragraph))
+			paragraphs = append(paragraphs, paragraph)
+			return ast.WalkSkipChildren, nil
+		case *ast.CodeBlock, *ast.FencedCodeBlock:
+			lines := node.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				line := lines.At(i)
+				words += len(strings.Fields(string(line.Value(source))))
+			}
+			return ast.WalkSkipChildren, nil
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return &Summary{
+		ReadingTime:     readingTime(words),
+		Excerpt:         excerpt(paragraphs),
+		TableOfContents: headings,
+	}
+}
+
+// nodeText returns the text of an inline tree without markup
+func nodeText(n ast.Node, source []byte) string {
+	var b strings.Builder
+	_ = ast.Walk(n, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.Text:
+			b.Write(node.Segment.Value(source))
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(node.Value)
+		case *ast.AutoLink:
+			b.Write(node.Label(source))
+		case *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return strings.TrimSpace(b.String())
+}
+
+func readingTime(words int) int {
+	if words == 0 {
+		return 0
+	}
+	return (words + wordsPerMinute - 1) / wordsPerMinute
+}
+
+// excerpt joins the paragraphs and cuts the text on a word boundary
+func excerpt(paragraphs []string) string {
+	result := strings.Join(strings.Fields(strings.Join(paragraphs, " ")), " ")
+	if utf8.RuneCountInString(result) <= excerptLength {
+		return result
+	}
+
+	runes := []rune(result)[:excerptLength]
+	cut := string(runes)
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+
+	return strings.TrimRight(cut, " .,;:!?-") + "…"
+}
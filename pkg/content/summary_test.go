@@ -0,0 +1,50 @@
+package content
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeMarkdown(t *testing.T) {
+	source := "# Салом дунё\n\nFirst **bold** [link](http://example.com) paragraph.\n\n" +
+		"## Intro\n\n<div>raw</div>\n\n```go\nfunc main() {}\n```\n\n## Intro\n\nSecond `code` paragraph.\n"
+
+	summary, err := Summarize(FormatMarkdown, source)
+	require.NoError(t, err)
+	require.Equal(t, 1, summary.ReadingTime)
+	require.Equal(t, "First bold link paragraph. Second code paragraph.", summary.Excerpt)
+	require.Equal(t, []Heading{
+		{Level: 1, Text: "Салом дунё", Id: "salom-dunyo"},
+		{Level: 2, Text: "Intro", Id: "intro"},
+		{Level: 2, Text: "Intro", Id: "intro-1"},
+	}, summary.TableOfContents)
+
+	html, err := Render(FormatMarkdown, source)
+	require.NoError(t, err)
+	for _, heading := range summary.TableOfContents {
+		require.Contains(t, html, `id="`+heading.Id+`"`)
+	}
+}
+
+func TestSummarizePlain(t *testing.T) {
+	source := strings.Repeat("word ", 450)
+
+	summary, err := Summarize(FormatPlain, source)
+	require.NoError(t, err)
+	require.Equal(t, 3, summary.ReadingTime)
+	require.Empty(t, summary.TableOfContents)
+	require.True(t, strings.HasSuffix(summary.Excerpt, "word…"))
+	require.LessOrEqual(t, len([]rune(summary.Excerpt)), excerptLength+1)
+
+	summary, err = Summarize(FormatPlain, "")
+	require.NoError(t, err)
+	require.Equal(t, 0, summary.ReadingTime)
+	require.Equal(t, "", summary.Excerpt)
+}
+
+func TestSummarizeUnknownFormat(t *testing.T) {
+	_, err := Summarize("html", "<p>hi</p>")
+	require.ErrorIs(t, err, ErrUnknownFormat)
+}
@@ -0,0 +1,110 @@
+package diff
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	OpEqual  = "equal"
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+// MaxLines is the most lines the changed part of each text can have. The
+// LCS table grows with the product of both, so bigger inputs are refused
+const MaxLines = 1000
+
+var ErrTooLarge = errors.New("the texts differ in too many lines to diff")
+
+type Line struct {
+	Op   string
+	Text string
+}
+
+// Lines returns a line based diff that turns a into b.
+// It is built from the longest common subsequence of both texts, after the
+// lines they start and end with are left out. It returns ErrTooLarge if
+// more than MaxLines lines are left in a or b
+func Lines(a, b string) ([]Line, error) {
+	linesA, linesB := splitLines(a), splitLines(b)
+
+	prefix := 0
+	for prefix < len(linesA) && prefix < len(linesB) && linesA[prefix] == linesB[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(linesA)-prefix && suffix < len(linesB)-prefix &&
+		linesA[len(linesA)-1-suffix] == linesB[len(linesB)-1-suffix] {
+		suffix++
+	}
+
+	changedA := linesA[prefix : len(linesA)-suffix]
+	changedB := linesB[prefix : len(linesB)-suffix]
+	if len(changedA) > MaxLines || len(changedB) > MaxLines {
+		return nil, ErrTooLarge
+	}
+
+	result := make([]Line, 0, len(linesA)+len(changedB))
+	for _, line := range linesA[:prefix] {
+		result = append(result, Line{Op: OpEqual, Text: line})
+	}
+	result = append(result, diff(changedA, changedB)...)
+	for _, line := range linesA[len(linesA)-suffix:] {
+		result = append(result, Line{Op: OpEqual, Text: line})
+	}
+
+	return result, nil
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+func diff(a, b []string) []Line {
+	// lcs[i][j] is the length of the LCS of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	result := make([]Line, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			result = append(result, Line{Op: OpEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			result = append(result, Line{Op: OpDelete, Text: a[i]})
+			i++
+		default:
+			result = append(result, Line{Op: OpInsert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		result = append(result, Line{Op: OpDelete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		result = append(result, Line{Op: OpInsert, Text: b[j]})
+	}
+
+	return result
+}
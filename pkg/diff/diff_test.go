@@ -0,0 +1,67 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Line
+	}{
+		{
+			name: "empty",
+			want: []Line{},
+		},
+		{
+			name: "equal",
+			a:    "one\ntwo",
+			b:    "one\ntwo\n",
+			want: []Line{{OpEqual, "one"}, {OpEqual, "two"}},
+		},
+		{
+			name: "insert",
+			a:    "one\nthree",
+			b:    "one\ntwo\nthree",
+			want: []Line{{OpEqual, "one"}, {OpInsert, "two"}, {OpEqual, "three"}},
+		},
+		{
+			name: "delete",
+			a:    "one\ntwo\nthree",
+			b:    "one\nthree",
+			want: []Line{{OpEqual, "one"}, {OpDelete, "two"}, {OpEqual, "three"}},
+		},
+		{
+			name: "replace",
+			a:    "one\ntwo",
+			b:    "one\r\n2",
+			want: []Line{{OpEqual, "one"}, {OpDelete, "two"}, {OpInsert, "2"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, err := Lines(tt.a, tt.b)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, lines)
+		})
+	}
+}
+
+func TestLinesTooLarge(t *testing.T) {
+	a := strings.Repeat("a\n", MaxLines+1)
+	b := strings.Repeat("b\n", MaxLines+1)
+
+	_, err := Lines(a, b)
+	require.ErrorIs(t, err, ErrTooLarge)
+
+	// unchanged lines around the edit do not count
+	lines, err := Lines("start\n"+a+"end", "start\n"+a+"changed\nend")
+	require.NoError(t, err)
+	require.Len(t, lines, MaxLines+4)
+	require.Equal(t, Line{OpInsert, "changed"}, lines[MaxLines+2])
+}
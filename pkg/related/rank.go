@@ -0,0 +1,157 @@
+package related
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/post/storage/repo"
+)
+
+// Weights of the signals in the score of a candidate, each signal is in [0, 1]
+const (
+	categoryWeight = 1.0
+	tagsWeight     = 3.0
+	termsWeight    = 2.0
+	coLikesWeight  = 2.0
+)
+
+// Rank scores the candidates against the source and returns the ids of the
+// best limit of them
+func Rank(c *repo.RelatedCandidates, limit int) []int {
+	type scored struct {
+		id    int
+		score float64
+	}
+
+	docs := make([][]string, 0, len(c.Candidates)+1)
+	docs = append(docs, terms(c.Source))
+	for _, p := range c.Candidates {
+		docs = append(docs, terms(p))
+	}
+	vectors := tfidf(docs)
+
+	maxCoLikes := 0
+	for _, p := range c.Candidates {
+		if p.CoLikes > maxCoLikes {
+			maxCoLikes = p.CoLikes
+		}
+	}
+
+	ranked := make([]scored, 0, len(c.Candidates))
+	for i, p := range c.Candidates {
+		score := termsWeight * cosine(vectors[0], vectors[i+1])
+		score += tagsWeight * tagOverlap(c, p)
+		if p.CategoryID == c.Source.CategoryID {
+			score += categoryWeight
+		}
+		if maxCoLikes > 0 {
+			score += coLikesWeight * float64(p.CoLikes) / float64(maxCoLikes)
+		}
+		ranked = append(ranked, scored{id: p.PostID, score: score})
+	}
+
+	// newer posts win ties
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].id > ranked[j].id
+	})
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	ids := make([]int, 0, len(ranked))
+	for _, r := range ranked {
+		ids = append(ids, r.id)
+	}
+	return ids
+}
+
+// tagOverlap is the share of the source's tag weight the post has. Tags
+// are weighted by inverse document frequency, so sharing a rare tag counts
+// more than sharing a common one
+func tagOverlap(c *repo.RelatedCandidates, p *repo.RelatedPost) float64 {
+	shared := make(map[string]bool, len(p.Tags))
+	for _, t := range p.Tags {
+		shared[t] = true
+	}
+
+	var total, overlap float64
+	for _, t := range c.Source.Tags {
+		w := idf(c.PostsCount, c.TagPosts[t])
+		total += w
+		if shared[t] {
+			overlap += w
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return overlap / total
+}
+
+// terms splits the title and description into lowercase words. Title words
+// are counted twice, as the search vector weighs the title higher too
+func terms(p *repo.RelatedPost) []string {
+	split := func(s string) []string {
+		return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+	}
+
+	title := split(p.Title)
+	result := append(append(make([]string, 0), title...), title...)
+	return append(result, split(p.Description)...)
+}
+
+// tfidf weighs the terms of each document. Document frequencies are taken
+// over the given documents, that is the source and its candidates
+func tfidf(docs [][]string) []map[string]float64 {
+	df := make(map[string]int)
+	for _, doc := range docs {
+		seen := make(map[string]bool, len(doc))
+		for _, t := range doc {
+			if !seen[t] {
+				seen[t] = true
+				df[t]++
+			}
+		}
+	}
+
+	vectors := make([]map[string]float64, 0, len(docs))
+	for _, doc := range docs {
+		v := make(map[string]float64, len(doc))
+		for _, t := range doc {
+			v[t]++
+		}
+		for t, tf := range v {
+			v[t] = tf / float64(len(doc)) * idf(len(docs), df[t])
+		}
+		vectors = append(vectors, v)
+	}
+	return vectors
+}
+
+// idf is the smoothed inverse document frequency of a term found in df of n
+// documents
+func idf(n, df int) float64 {
+	return math.Log(float64(1+n)/float64(1+df)) + 1
+}
+
+func cosine(a, b map[string]float64) float64 {
+	var dot, na, nb float64
+	for t, w := range a {
+		dot += w * b[t]
+		na += w * w
+	}
+	for _, w := range b {
+		nb += w * w
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
@@ -0,0 +1,81 @@
+package related
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/post/storage"
+)
+
+const (
+	// the generation is bumped on every post update, which drops all
+	// cached lists at once since ranks depend on the other posts too
+	generationKey   = "related_posts"
+	generationField = "generation"
+	cacheKeyPrefix  = "related_posts_"
+	cacheTTLMinutes = 30
+
+	// MaxRelated is the longest list of related posts that is kept
+	MaxRelated      = 20
+	candidatesLimit = 50
+)
+
+// Recommender ranks related posts and caches the result in Redis
+type Recommender struct {
+	storage  storage.StorageI
+	inMemory storage.InMemoryStorageI
+}
+
+func NewRecommender(strg storage.StorageI, inMemory storage.InMemoryStorageI) *Recommender {
+	return &Recommender{
+		storage:  strg,
+		inMemory: inMemory,
+	}
+}
+
+// Get returns the ids of up to limit posts related to the post, best first
+func (r *Recommender) Get(ctx context.Context, postID, limit int) ([]int, error) {
+	generation, err := r.inMemory.HGet(ctx, generationKey, generationField)
+	if err != nil && !errors.Is(err, storage.ErrKeyNotFound) {
+		return nil, err
+	}
+	key := cacheKeyPrefix + generation + "_" + strconv.Itoa(postID)
+
+	var ids []int
+	cached, err := r.inMemory.Get(ctx, key)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal([]byte(cached), &ids); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, storage.ErrKeyNotFound):
+		candidates, err := r.storage.Post().GetRelatedCandidates(ctx, postID, candidatesLimit)
+		if err != nil {
+			return nil, err
+		}
+		ids = Rank(candidates, MaxRelated)
+
+		data, err := json.Marshal(ids)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.inMemory.SetWithTTL(ctx, key, string(data), cacheTTLMinutes); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+// Invalidate drops all cached related posts
+func (r *Recommender) Invalidate(ctx context.Context) error {
+	_, err := r.inMemory.HIncrBy(ctx, generationKey, generationField, 1)
+	return err
+}
@@ -0,0 +1,142 @@
+package related
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/post/storage"
+	"github.com/post/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeInMemory struct {
+	storage.InMemoryStorageI
+	values map[string]string
+	hashes map[string]map[string]int
+}
+
+func (f *fakeInMemory) Get(ctx context.Context, key string) (string, error) {
+	val, ok := f.values[key]
+	if !ok {
+		return "", storage.ErrKeyNotFound
+	}
+	return val, nil
+}
+
+func (f *fakeInMemory) SetWithTTL(ctx context.Context, key, value string, n int) error {
+	f.values[key] = value
+	return nil
+}
+
+func (f *fakeInMemory) HGet(ctx context.Context, key, field string) (string, error) {
+	val, ok := f.hashes[key][field]
+	if !ok {
+		return "", storage.ErrKeyNotFound
+	}
+	return strconv.Itoa(val), nil
+}
+
+func (f *fakeInMemory) HIncrBy(ctx context.Context, key, field string, n int) (int, error) {
+	if f.hashes[key] == nil {
+		f.hashes[key] = make(map[string]int)
+	}
+	f.hashes[key][field] += n
+	return f.hashes[key][field], nil
+}
+
+type fakePosts struct {
+	repo.PostStorageI
+	candidates *repo.RelatedCandidates
+	calls      int
+}
+
+func (f *fakePosts) GetRelatedCandidates(ctx context.Context, postID, limit int) (*repo.RelatedCandidates, error) {
+	f.calls++
+	return f.candidates, nil
+}
+
+type fakeStorage struct {
+	storage.StorageI
+	posts *fakePosts
+}
+
+func (f *fakeStorage) Post() repo.PostStorageI {
+	return f.posts
+}
+
+func candidates() *repo.RelatedCandidates {
+	return &repo.RelatedCandidates{
+		Source: &repo.RelatedPost{
+			PostID:      1,
+			CategoryID:  1,
+			Title:       "Tuning Postgres indexes",
+			Description: "How btree and gin indexes speed up queries",
+			Tags:        []string{"postgres", "databases"},
+		},
+		Candidates: []*repo.RelatedPost{
+			{PostID: 2, CategoryID: 2, Title: "Baking bread", Description: "Flour, water and time"},
+			{PostID: 3, CategoryID: 1, Title: "Gardening", Description: "Plants need water"},
+			{
+				PostID:      4,
+				CategoryID:  1,
+				Title:       "Postgres gin indexes",
+				Description: "When a gin index beats a btree",
+				Tags:        []string{"postgres"},
+			},
+			{PostID: 5, CategoryID: 2, Title: "Databases", Description: "A tour", Tags: []string{"databases"}},
+		},
+		TagPosts:   map[string]int{"postgres": 3, "databases": 40},
+		PostsCount: 100,
+	}
+}
+
+func TestRank(t *testing.T) {
+	// a shared common tag counts for less than the category
+	ids := Rank(candidates(), 10)
+	require.Equal(t, []int{4, 3, 5, 2}, ids)
+
+	require.Equal(t, []int{4, 3}, Rank(candidates(), 2))
+}
+
+func TestRankCoLikes(t *testing.T) {
+	c := candidates()
+	c.Candidates[0].CoLikes = 10
+
+	// readers who liked the source liked post 2 too, which puts it above
+	// the post that only shares the category
+	ids := Rank(c, 10)
+	require.Equal(t, []int{4, 2, 3, 5}, ids)
+}
+
+func TestRankRareTag(t *testing.T) {
+	c := candidates()
+	c.Candidates[2].Title, c.Candidates[2].Description = "Notes", ""
+	c.Candidates[2].CategoryID = 2
+
+	// postgres is on far fewer posts than databases, so sharing it counts more
+	require.Greater(t, tagOverlap(c, c.Candidates[2]), tagOverlap(c, c.Candidates[3]))
+}
+
+func TestRecommenderCache(t *testing.T) {
+	ctx := context.Background()
+	posts := &fakePosts{candidates: candidates()}
+	r := NewRecommender(
+		&fakeStorage{posts: posts},
+		&fakeInMemory{values: make(map[string]string), hashes: make(map[string]map[string]int)},
+	)
+
+	ids, err := r.Get(ctx, 1, 2)
+	require.NoError(t, err)
+	require.Equal(t, []int{4, 3}, ids)
+
+	ids, err = r.Get(ctx, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, []int{4, 3, 5, 2}, ids)
+	require.Equal(t, 1, posts.calls)
+
+	require.NoError(t, r.Invalidate(ctx))
+	_, err = r.Get(ctx, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, posts.calls)
+}
@@ -0,0 +1,123 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/post/config"
+	"github.com/post/pkg/related"
+	"github.com/post/storage"
+)
+
+const (
+	publishLockKey          = "scheduler_publish_lock"
+	rankingsLockKey         = "scheduler_rankings_lock"
+	defaultInterval         = 30 * time.Second
+	defaultBatchSize        = 100
+	defaultRankingsInterval = 5 * time.Minute
+)
+
+type Scheduler struct {
+	storage          storage.StorageI
+	inMemory         storage.InMemoryStorageI
+	related          *related.Recommender
+	interval         time.Duration
+	batchSize        int
+	rankingsInterval time.Duration
+	owner            string
+}
+
+func New(cfg *config.Config, strg storage.StorageI, inMemory storage.InMemoryStorageI) *Scheduler {
+	host, _ := os.Hostname()
+
+	s := &Scheduler{
+		storage:          strg,
+		inMemory:         inMemory,
+		related:          related.NewRecommender(strg, inMemory),
+		interval:         cfg.Scheduler.Interval,
+		batchSize:        cfg.Scheduler.BatchSize,
+		rankingsInterval: cfg.Scheduler.RankingsInterval,
+		owner:            host + "_" + strconv.Itoa(os.Getpid()),
+	}
+	if s.interval <= 0 {
+		s.interval = defaultInterval
+	}
+	if s.batchSize <= 0 {
+		s.batchSize = defaultBatchSize
+	}
+	if s.rankingsInterval <= 0 {
+		s.rankingsInterval = defaultRankingsInterval
+	}
+
+	return s
+}
+
+// Run publishes due scheduled posts every interval and refreshes post
+// rankings every rankings interval until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	rankings := time.NewTicker(s.rankingsInterval)
+	defer rankings.Stop()
+
+	s.publishDue(ctx)
+	s.refreshRankings(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.publishDue(ctx)
+		case <-rankings.C:
+			s.refreshRankings(ctx)
+		}
+	}
+}
+
+func (s *Scheduler) refreshRankings(ctx context.Context) {
+	ok, err := s.inMemory.SetNX(ctx, rankingsLockKey, s.owner, s.rankingsInterval)
+	if err != nil {
+		log.Printf("scheduler: failed to acquire rankings lock: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	if err := s.storage.Post().RefreshRankings(ctx); err != nil {
+		log.Printf("scheduler: failed to refresh rankings: %v", err)
+	}
+}
+
+func (s *Scheduler) publishDue(ctx context.Context) {
+	// Only one replica runs each tick. The lock is never released
+	// explicitly, it expires together with the tick
+	ok, err := s.inMemory.SetNX(ctx, publishLockKey, s.owner, s.interval)
+	if err != nil {
+		log.Printf("scheduler: failed to acquire lock: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	for {
+		ids, err := s.storage.Post().PublishDue(ctx, time.Now(), s.batchSize)
+		if err != nil {
+			log.Printf("scheduler: failed to publish posts: %v", err)
+			return
+		}
+		if len(ids) > 0 {
+			log.Printf("scheduler: published posts %v", ids)
+			if err := s.related.Invalidate(ctx); err != nil {
+				log.Printf("scheduler: failed to invalidate related posts: %v", err)
+			}
+		}
+		if len(ids) < s.batchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	createdAt := time.Date(2022, 11, 20, 10, 30, 15, 123456000, time.FixedZone("UZT", 5*60*60))
+
+	cursor := EncodeCursor(createdAt, 42)
+	gotTime, gotID, err := DecodeCursor(cursor)
+	require.NoError(t, err)
+	require.True(t, createdAt.Equal(gotTime))
+	require.Equal(t, 42, gotID)
+}
+
+func TestDecodeInvalidCursor(t *testing.T) {
+	for _, cursor := range []string{
+		"",
+		"not base64!",
+		EncodeCursor(time.Now(), 1)[:4],
+		"MjAyMi0xMS0yMA",
+	} {
+		_, _, err := DecodeCursor(cursor)
+		require.ErrorIs(t, err, ErrInvalidCursor, cursor)
+	}
+}
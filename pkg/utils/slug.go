@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// cyrillic maps Uzbek and Russian cyrillic letters to the official uzbek latin alphabet
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "j", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "x", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "sh", 'ъ': "",
+	'ы': "i", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya", 'ў': "o", 'қ': "q",
+	'ғ': "g", 'ҳ': "h",
+}
+
+// apostrophes are used inside uzbek latin letters (o‘, g‘) and are dropped
+var apostrophes = map[rune]bool{
+	'\'': true, '‘': true, '’': true, 'ʻ': true, 'ʼ': true, '`': true,
+}
+
+// Transliterate converts cyrillic letters of s to latin
+func Transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		lower := unicode.ToLower(r)
+		latin, ok := cyrillic[lower]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		if lower != r && latin != "" {
+			latin = strings.ToUpper(latin[:1]) + latin[1:]
+		}
+		b.WriteString(latin)
+	}
+	return b.String()
+}
+
+// Slugify returns a lowercase, hyphen separated version of s
+// that is safe to use inside a URL path segment. Slugs are made of a-z and
+// 0-9 only, the rule the slugs backfilled by migration 000008 follow, so
+// cyrillic is transliterated and accents are dropped first. Other letters
+// are left out
+func Slugify(s string) string {
+	var b strings.Builder
+	hyphen := false
+
+	for _, r := range norm.NFD.String(strings.ToLower(Transliterate(strings.TrimSpace(s)))) {
+		switch {
+		case apostrophes[r] || unicode.Is(unicode.Mn, r):
+		case 'a' <= r && r <= 'z' || '0' <= r && r <= '9':
+			b.WriteRune(r)
+			hyphen = false
+		case b.Len() > 0 && !hyphen:
+			b.WriteByte('-')
+			hyphen = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
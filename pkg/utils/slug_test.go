@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Go", "go"},
+		{"  Machine Learning ", "machine-learning"},
+		{"C++ & Rust!", "c-rust"},
+		{"web--dev__tips", "web-dev-tips"},
+		{"---", ""},
+		{"O‘zbekiston bo'ylab sayohat", "ozbekiston-boylab-sayohat"},
+		{"Ўзбекистон ҳақида", "ozbekiston-haqida"},
+		{"Привет, мир!", "privet-mir"},
+		{"Café crème", "cafe-creme"},
+		{"Straße 日本 Go", "stra-e-go"},
+		{"日本", ""},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, Slugify(tt.in), tt.in)
+	}
+}
+
+func TestTransliterate(t *testing.T) {
+	require.Equal(t, "Toshkent shahri", Transliterate("Тошкент шаҳри"))
+	require.Equal(t, "Chorsu", Transliterate("Чорсу"))
+	require.Equal(t, "latin", Transliterate("latin"))
+}
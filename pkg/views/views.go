@@ -0,0 +1,273 @@
+package views
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/post/storage"
+	"github.com/post/storage/repo"
+)
+
+const (
+	viewersKeyPrefix = "post_viewers_"
+	readersKeyPrefix = "post_readers_"
+	readsKeyPrefix   = "post_reads_"
+	pendingKey       = "views_pending"
+	flushingKey      = "views_flushing"
+	flushLockKey     = "views_flush_lock"
+
+	defaultWindow        = 30 * time.Minute
+	defaultFlushInterval = 10 * time.Second
+
+	// Daily sets of readers live a day longer than their day
+	dailyTTL  = 48 * time.Hour
+	dayLayout = "20060102"
+)
+
+// Counters of the pending hash. Fields are metric:post_id:day,
+// referrers add :domain
+const (
+	metricViews     = "v"
+	metricReaders   = "u"
+	metricReads     = "r"
+	metricReferrers = "d"
+)
+
+// Counter counts post views once per viewer and time window, along with
+// the daily stats of posts. The counts are summed up in Redis and added
+// to Postgres in batches
+type Counter struct {
+	storage  storage.StorageI
+	inMemory storage.InMemoryStorageI
+	window   time.Duration
+	owner    string
+	now      func() time.Time
+}
+
+func NewCounter(strg storage.StorageI, inMemory storage.InMemoryStorageI, window time.Duration) *Counter {
+	host, _ := os.Hostname()
+	if window <= 0 {
+		window = defaultWindow
+	}
+
+	return &Counter{
+		storage:  strg,
+		inMemory: inMemory,
+		window:   window,
+		owner:    host + "_" + strconv.Itoa(os.Getpid()),
+		now:      time.Now,
+	}
+}
+
+// UserViewer identifies a signed in viewer
+func UserViewer(userID int) string {
+	return "u" + strconv.Itoa(userID)
+}
+
+// AnonymousViewer identifies a guest by a fingerprint of the client
+func AnonymousViewer(ip, userAgent string) string {
+	sum := sha256.Sum256([]byte(ip + "|" + userAgent))
+	return "a" + hex.EncodeToString(sum[:8])
+}
+
+// ReferrerDomain returns the domain of the referer URL, without "www.".
+// Links within the site itself (host) give ""
+func ReferrerDomain(referer, host string) string {
+	u, err := url.Parse(referer)
+	if err != nil {
+		return ""
+	}
+
+	domain := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if domain == "" || domain == strings.TrimPrefix(strings.ToLower(hostname(host)), "www.") {
+		return ""
+	}
+	return domain
+}
+
+func hostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
+
+// Record counts a view of the post unless the viewer has already been
+// counted in the current window. It reports whether the view was counted.
+// referrer is the domain the reader came from, if any
+func (c *Counter) Record(ctx context.Context, postID int, viewer, referrer string) (bool, error) {
+	now := c.now()
+	day := now.UTC().Format(dayLayout)
+
+	newReader, err := c.inMemory.SAdd(ctx, readersKeyPrefix+strconv.Itoa(postID)+"_"+day, viewer, dailyTTL)
+	if err != nil {
+		return false, err
+	}
+	if newReader {
+		if err := c.incr(ctx, metricReaders, postID, day); err != nil {
+			return false, err
+		}
+	}
+
+	bucket := now.Unix() / int64(c.window/time.Second)
+	key := viewersKeyPrefix + strconv.Itoa(postID) + "_" + strconv.FormatInt(bucket, 10)
+
+	added, err := c.inMemory.SAdd(ctx, key, viewer, c.window)
+	if err != nil || !added {
+		return false, err
+	}
+
+	if err := c.incr(ctx, metricViews, postID, day); err != nil {
+		return false, err
+	}
+	if referrer != "" {
+		if err := c.incr(ctx, metricReferrers, postID, day+":"+referrer); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// RecordRead counts a read to completion of the post, once a day per viewer
+func (c *Counter) RecordRead(ctx context.Context, postID int, viewer string) error {
+	day := c.now().UTC().Format(dayLayout)
+
+	added, err := c.inMemory.SAdd(ctx, readsKeyPrefix+strconv.Itoa(postID)+"_"+day, viewer, dailyTTL)
+	if err != nil || !added {
+		return err
+	}
+
+	return c.incr(ctx, metricReads, postID, day)
+}
+
+func (c *Counter) incr(ctx context.Context, metric string, postID int, day string) error {
+	_, err := c.inMemory.HIncrBy(ctx, pendingKey, metric+":"+strconv.Itoa(postID)+":"+day, 1)
+	return err
+}
+
+// Run flushes the counted views every interval until ctx is cancelled
+func (c *Counter) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultFlushInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// the lock is held for the whole flush, so no other replica
+		// applies the same batch
+		if _, err := storage.WithLock(ctx, c.inMemory, flushLockKey, c.owner, interval, c.Flush); err != nil {
+			log.Printf("views: failed to flush: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush adds the counted views and stats to Postgres. A batch left over
+// by a failed flush is retried before the current one
+func (c *Counter) Flush(ctx context.Context) error {
+	leftover, err := c.inMemory.HGetAll(ctx, flushingKey)
+	if err != nil {
+		return err
+	}
+	if len(leftover) == 0 {
+		ok, err := c.inMemory.Rename(ctx, pendingKey, flushingKey)
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	values, err := c.inMemory.HGetAll(ctx, flushingKey)
+	if err != nil {
+		return err
+	}
+
+	views, stats := parsePending(values)
+
+	err = c.storage.WithTx(ctx, func(tx storage.StorageI) error {
+		if err := tx.PostStats().AddDaily(ctx, stats); err != nil {
+			return err
+		}
+		return tx.Post().AddViews(ctx, views)
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.inMemory.Delete(ctx, flushingKey)
+}
+
+func parsePending(values map[string]string) (map[int]int, []*repo.PostDailyStats) {
+	views := make(map[int]int)
+	byDay := make(map[string]*repo.PostDailyStats)
+	stats := make([]*repo.PostDailyStats, 0)
+
+	for field, val := range values {
+		parts := strings.SplitN(field, ":", 4)
+		if len(parts) < 3 {
+			log.Printf("views: skipping %q: invalid field", field)
+			continue
+		}
+
+		postID, err := strconv.Atoi(parts[1])
+		if err != nil {
+			log.Printf("views: skipping %q: %v", field, err)
+			continue
+		}
+		day, err := time.Parse(dayLayout, parts[2])
+		if err != nil {
+			log.Printf("views: skipping %q: %v", field, err)
+			continue
+		}
+		count, err := strconv.Atoi(val)
+		if err != nil {
+			log.Printf("views: skipping %q: %v", field, err)
+			continue
+		}
+
+		key := parts[1] + ":" + parts[2]
+		s, ok := byDay[key]
+		if !ok {
+			s = &repo.PostDailyStats{
+				PostID:    postID,
+				Day:       day,
+				Referrers: make(map[string]int),
+			}
+			byDay[key] = s
+			stats = append(stats, s)
+		}
+
+		switch parts[0] {
+		case metricViews:
+			s.Views += count
+			views[postID] += count
+		case metricReaders:
+			s.UniqueReaders += count
+		case metricReads:
+			s.Reads += count
+		case metricReferrers:
+			if len(parts) == 4 {
+				s.Referrers[parts[3]] += count
+			}
+		default:
+			log.Printf("views: skipping %q: unknown metric", field)
+		}
+	}
+
+	return views, stats
+}
@@ -0,0 +1,191 @@
+package views
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/post/storage"
+	"github.com/post/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeInMemory struct {
+	storage.InMemoryStorageI
+	sets   map[string]map[string]bool
+	hashes map[string]map[string]string
+}
+
+func (f *fakeInMemory) SAdd(ctx context.Context, key, member string, ttl time.Duration) (bool, error) {
+	if f.sets[key] == nil {
+		f.sets[key] = make(map[string]bool)
+	}
+	if f.sets[key][member] {
+		return false, nil
+	}
+	f.sets[key][member] = true
+	return true, nil
+}
+
+func (f *fakeInMemory) HIncrBy(ctx context.Context, key, field string, n int) (int, error) {
+	if f.hashes[key] == nil {
+		f.hashes[key] = make(map[string]string)
+	}
+	val, _ := strconv.Atoi(f.hashes[key][field])
+	val += n
+	f.hashes[key][field] = strconv.Itoa(val)
+	return val, nil
+}
+
+func (f *fakeInMemory) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	result := make(map[string]string)
+	for k, v := range f.hashes[key] {
+		result[k] = v
+	}
+	return result, nil
+}
+
+func (f *fakeInMemory) Rename(ctx context.Context, key, newKey string) (bool, error) {
+	if _, ok := f.hashes[key]; !ok {
+		return false, nil
+	}
+	f.hashes[newKey] = f.hashes[key]
+	delete(f.hashes, key)
+	return true, nil
+}
+
+func (f *fakeInMemory) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(f.hashes, key)
+	}
+	return nil
+}
+
+type fakePosts struct {
+	repo.PostStorageI
+	views map[int]int
+}
+
+func (f *fakePosts) AddViews(ctx context.Context, views map[int]int) error {
+	for id, n := range views {
+		f.views[id] += n
+	}
+	return nil
+}
+
+type fakeStats struct {
+	repo.PostStatsStorageI
+	stats []*repo.PostDailyStats
+}
+
+func (f *fakeStats) AddDaily(ctx context.Context, stats []*repo.PostDailyStats) error {
+	f.stats = append(f.stats, stats...)
+	return nil
+}
+
+type fakeStorage struct {
+	storage.StorageI
+	posts *fakePosts
+	stats *fakeStats
+}
+
+func (f *fakeStorage) WithTx(ctx context.Context, fn func(storage.StorageI) error) error {
+	return fn(f)
+}
+
+func (f *fakeStorage) Post() repo.PostStorageI {
+	return f.posts
+}
+
+func (f *fakeStorage) PostStats() repo.PostStatsStorageI {
+	return f.stats
+}
+
+func newTestCounter(window time.Duration) (*Counter, *fakePosts, *fakeStats) {
+	posts := &fakePosts{views: make(map[int]int)}
+	stats := &fakeStats{}
+	inMemory := &fakeInMemory{
+		sets:   make(map[string]map[string]bool),
+		hashes: make(map[string]map[string]string),
+	}
+	return NewCounter(&fakeStorage{posts: posts, stats: stats}, inMemory, window), posts, stats
+}
+
+func TestCounter(t *testing.T) {
+	ctx := context.Background()
+	c, posts, _ := newTestCounter(time.Hour)
+
+	now := time.Date(2022, 11, 20, 10, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	record := func(postID int, viewer string, counted bool) {
+		ok, err := c.Record(ctx, postID, viewer, "")
+		require.NoError(t, err)
+		require.Equal(t, counted, ok)
+	}
+
+	guest := AnonymousViewer("10.0.0.1", "curl/7.86")
+	record(1, UserViewer(1), true)
+	record(1, UserViewer(1), false)
+	record(1, guest, true)
+	record(1, AnonymousViewer("10.0.0.1", "curl/7.86"), false)
+	record(2, UserViewer(1), true)
+
+	require.NoError(t, c.Flush(ctx))
+	require.Equal(t, map[int]int{1: 2, 2: 1}, posts.views)
+
+	now = now.Add(time.Hour)
+	record(1, UserViewer(1), true)
+
+	require.NoError(t, c.Flush(ctx))
+	require.Equal(t, map[int]int{1: 3, 2: 1}, posts.views)
+
+	require.NoError(t, c.Flush(ctx))
+	require.Equal(t, map[int]int{1: 3, 2: 1}, posts.views)
+}
+
+func TestCounterDailyStats(t *testing.T) {
+	ctx := context.Background()
+	c, _, stats := newTestCounter(time.Hour)
+
+	day := time.Date(2022, 11, 20, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return day.Add(10 * time.Hour) }
+
+	_, err := c.Record(ctx, 1, UserViewer(1), "google.com")
+	require.NoError(t, err)
+	_, err = c.Record(ctx, 1, UserViewer(2), "")
+	require.NoError(t, err)
+	require.NoError(t, c.RecordRead(ctx, 1, UserViewer(1)))
+	require.NoError(t, c.RecordRead(ctx, 1, UserViewer(1)))
+
+	// a new window of the same day: a view but not a new reader
+	c.now = func() time.Time { return day.Add(12 * time.Hour) }
+	_, err = c.Record(ctx, 1, UserViewer(1), "google.com")
+	require.NoError(t, err)
+
+	require.NoError(t, c.Flush(ctx))
+	require.Equal(t, []*repo.PostDailyStats{{
+		PostID:        1,
+		Day:           day,
+		Views:         3,
+		UniqueReaders: 2,
+		Reads:         1,
+		Referrers:     map[string]int{"google.com": 2},
+	}}, stats.stats)
+}
+
+func TestReferrerDomain(t *testing.T) {
+	for _, tc := range []struct {
+		referer, host, want string
+	}{
+		{"https://www.Google.com/search?q=go", "blog.uz", "google.com"},
+		{"https://t.co/abc", "blog.uz:8000", "t.co"},
+		{"http://blog.uz:8000/v1/posts", "blog.uz:8000", ""},
+		{"https://www.blog.uz/", "blog.uz", ""},
+		{"", "blog.uz", ""},
+		{"::not a url", "blog.uz", ""},
+	} {
+		require.Equal(t, tc.want, ReferrerDomain(tc.referer, tc.host), tc.referer)
+	}
+}
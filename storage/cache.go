@@ -0,0 +1,417 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/post/config"
+	"github.com/post/storage/repo"
+)
+
+const (
+	postCacheKey     = "cache_post_"
+	profileCacheKey  = "cache_profile_"
+	categoryCacheKey = "cache_category_"
+)
+
+// NewCachedStorage decorates strg with a read-through cache of posts, user
+// profiles and categories kept in inMemory. Entries are dropped by the writes
+// that change them, with a few exceptions that last at most the post TTL:
+// views_count, since views are flushed too often to drop hot posts on each
+// flush, bookmarks_count after a whole reading list is deleted, and counters
+// fixed by RecountCounters. A write racing a cache miss can also leave a
+// stale entry until it expires
+func NewCachedStorage(strg StorageI, inMemory InMemoryStorageI, cfg config.CacheConfig) StorageI {
+	c := &cache{inMemory: inMemory}
+
+	return &storageCached{
+		StorageI: strg,
+		inMemory: inMemory,
+		cfg:      cfg,
+		postRepo: &cachedPostRepo{
+			PostStorageI: strg.Post(),
+			cache:        c,
+			ttl:          cfg.PostTTL,
+		},
+		userRepo: &cachedUserRepo{
+			UserStorageI: strg.User(),
+			cache:        c,
+			ttl:          cfg.ProfileTTL,
+		},
+		categoryRepo: &cachedCategoryRepo{
+			CategoryStorageI: strg.Category(),
+			cache:            c,
+			ttl:              cfg.CategoryTTL,
+		},
+		tagRepo:     &cachedTagRepo{TagStorageI: strg.Tag(), cache: c},
+		likeRepo:    &cachedLikeRepo{LikeStorageI: strg.Like(), cache: c},
+		commentRepo: &cachedCommentRepo{CommentStorageI: strg.Comment(), cache: c},
+		clapRepo:    &cachedClapRepo{ClapStorageI: strg.Clap(), cache: c},
+		listRepo:    &cachedReadingListRepo{ReadingListStorageI: strg.ReadingList(), cache: c},
+	}
+}
+
+type storageCached struct {
+	StorageI
+	inMemory     InMemoryStorageI
+	cfg          config.CacheConfig
+	postRepo     repo.PostStorageI
+	userRepo     repo.UserStorageI
+	categoryRepo repo.CategoryStorageI
+	tagRepo      repo.TagStorageI
+	likeRepo     repo.LikeStorageI
+	commentRepo  repo.CommentStorageI
+	clapRepo     repo.ClapStorageI
+	listRepo     repo.ReadingListStorageI
+}
+
+// WithTx keeps the transaction off the cache: its reads go to the database,
+// so they see its own writes, and the entries its writes drop are only
+// deleted once it commits
+func (s *storageCached) WithTx(ctx context.Context, fn func(StorageI) error) error {
+	m := &txInMemory{InMemoryStorageI: s.inMemory}
+	err := s.StorageI.WithTx(ctx, func(tx StorageI) error {
+		return fn(NewCachedStorage(tx, m, s.cfg))
+	})
+	if err != nil {
+		return err
+	}
+	return s.inMemory.Delete(ctx, m.drop...)
+}
+
+// txInMemory is the cache seen by a transaction: every read misses, nothing
+// is stored and deleted keys are collected in drop
+type txInMemory struct {
+	InMemoryStorageI
+	drop []string
+}
+
+func (m *txInMemory) Get(ctx context.Context, key string) (string, error) {
+	return "", ErrKeyNotFound
+}
+
+func (m *txInMemory) MGet(ctx context.Context, keys ...string) (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
+func (m *txInMemory) SetWithTTL(ctx context.Context, key, value string, n int) error {
+	return nil
+}
+
+func (m *txInMemory) Delete(ctx context.Context, keys ...string) error {
+	m.drop = append(m.drop, keys...)
+	return nil
+}
+
+func (s *storageCached) Post() repo.PostStorageI {
+	return s.postRepo
+}
+
+func (s *storageCached) User() repo.UserStorageI {
+	return s.userRepo
+}
+
+func (s *storageCached) Category() repo.CategoryStorageI {
+	return s.categoryRepo
+}
+
+func (s *storageCached) Tag() repo.TagStorageI {
+	return s.tagRepo
+}
+
+func (s *storageCached) Like() repo.LikeStorageI {
+	return s.likeRepo
+}
+
+func (s *storageCached) Comment() repo.CommentStorageI {
+	return s.commentRepo
+}
+
+func (s *storageCached) Clap() repo.ClapStorageI {
+	return s.clapRepo
+}
+
+func (s *storageCached) ReadingList() repo.ReadingListStorageI {
+	return s.listRepo
+}
+
+type cache struct {
+	inMemory InMemoryStorageI
+}
+
+// cached returns the value stored under key, or loads it and stores it for
+// ttl. The cache is best effort, reads fall back to load if Redis fails
+func cached[T any](ctx context.Context, c *cache, key string, ttl time.Duration, load func() (*T, error)) (*T, error) {
+	if data, err := c.inMemory.Get(ctx, key); err == nil {
+		var v T
+		if err := json.Unmarshal([]byte(data), &v); err == nil {
+			return &v, nil
+		}
+	}
+
+	v, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	c.set(ctx, key, v, ttl)
+	return v, nil
+}
+
+// cachedMany is cached for a batch of ids: the stored values are read with
+// one MGet and only the missing ids are passed to load. Ids load does not
+// return are left out of the result
+func cachedMany[T any](ctx context.Context, c *cache, prefix string, ids []int, ttl time.Duration, load func(ids []int) (map[int]*T, error)) (map[int]*T, error) {
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, prefix+strconv.Itoa(id))
+	}
+
+	values, err := c.inMemory.MGet(ctx, keys...)
+	if err != nil {
+		values = nil
+	}
+
+	result := make(map[int]*T, len(ids))
+	missing := make([]int, 0, len(ids))
+	for i, id := range ids {
+		if data, ok := values[keys[i]]; ok {
+			var v T
+			if err := json.Unmarshal([]byte(data), &v); err == nil {
+				result[id] = &v
+				continue
+			}
+		}
+		missing = append(missing, id)
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	loaded, err := load(missing)
+	if err != nil {
+		return nil, err
+	}
+	for id, v := range loaded {
+		result[id] = v
+		c.set(ctx, prefix+strconv.Itoa(id), v, ttl)
+	}
+	return result, nil
+}
+
+func (c *cache) set(ctx context.Context, key string, v interface{}, ttl time.Duration) {
+	if data, err := json.Marshal(v); err == nil {
+		// SetWithTTL takes whole minutes, and 0 would never expire
+		minutes := int(ttl / time.Minute)
+		if minutes < 1 {
+			minutes = 1
+		}
+		_ = c.inMemory.SetWithTTL(ctx, key, string(data), minutes)
+	}
+}
+
+func (c *cache) dropPosts(ctx context.Context, ids ...int) error {
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, postCacheKey+strconv.Itoa(id))
+	}
+	return c.inMemory.Delete(ctx, keys...)
+}
+
+type cachedPostRepo struct {
+	repo.PostStorageI
+	cache *cache
+	ttl   time.Duration
+}
+
+func (r *cachedPostRepo) Get(ctx context.Context, id int) (*repo.Post, error) {
+	return cached(ctx, r.cache, postCacheKey+strconv.Itoa(id), r.ttl, func() (*repo.Post, error) {
+		return r.PostStorageI.Get(ctx, id)
+	})
+}
+
+func (r *cachedPostRepo) GetMany(ctx context.Context, ids []int) (map[int]*repo.Post, error) {
+	return cachedMany(ctx, r.cache, postCacheKey, ids, r.ttl, func(ids []int) (map[int]*repo.Post, error) {
+		return r.PostStorageI.GetMany(ctx, ids)
+	})
+}
+
+func (r *cachedPostRepo) Update(ctx context.Context, p *repo.Post) (*repo.Post, error) {
+	post, err := r.PostStorageI.Update(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	return post, r.cache.dropPosts(ctx, p.Id)
+}
+
+func (r *cachedPostRepo) Delete(ctx context.Context, id int) error {
+	if err := r.PostStorageI.Delete(ctx, id); err != nil {
+		return err
+	}
+	return r.cache.dropPosts(ctx, id)
+}
+
+func (r *cachedPostRepo) UpdateStatus(ctx context.Context, id int, status string) (*repo.Post, error) {
+	post, err := r.PostStorageI.UpdateStatus(ctx, id, status)
+	if err != nil {
+		return nil, err
+	}
+	return post, r.cache.dropPosts(ctx, id)
+}
+
+func (r *cachedPostRepo) PublishDue(ctx context.Context, now time.Time, limit int) ([]int, error) {
+	ids, err := r.PostStorageI.PublishDue(ctx, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return ids, r.cache.dropPosts(ctx, ids...)
+}
+
+type cachedUserRepo struct {
+	repo.UserStorageI
+	cache *cache
+	ttl   time.Duration
+}
+
+func (r *cachedUserRepo) GetUserProfileInfo(ctx context.Context, usrId int) (*repo.User, error) {
+	return cached(ctx, r.cache, profileCacheKey+strconv.Itoa(usrId), r.ttl, func() (*repo.User, error) {
+		return r.UserStorageI.GetUserProfileInfo(ctx, usrId)
+	})
+}
+
+func (r *cachedUserRepo) GetUserProfiles(ctx context.Context, ids []int) (map[int]*repo.User, error) {
+	return cachedMany(ctx, r.cache, profileCacheKey, ids, r.ttl, func(ids []int) (map[int]*repo.User, error) {
+		return r.UserStorageI.GetUserProfiles(ctx, ids)
+	})
+}
+
+func (r *cachedUserRepo) Update(ctx context.Context, usr *repo.User) (*repo.User, error) {
+	user, err := r.UserStorageI.Update(ctx, usr)
+	if err != nil {
+		return nil, err
+	}
+	return user, r.cache.inMemory.Delete(ctx, profileCacheKey+strconv.Itoa(usr.Id))
+}
+
+func (r *cachedUserRepo) Delete(ctx context.Context, id int) error {
+	if err := r.UserStorageI.Delete(ctx, id); err != nil {
+		return err
+	}
+	return r.cache.inMemory.Delete(ctx, profileCacheKey+strconv.Itoa(id))
+}
+
+type cachedCategoryRepo struct {
+	repo.CategoryStorageI
+	cache *cache
+	ttl   time.Duration
+}
+
+func (r *cachedCategoryRepo) Get(ctx context.Context, id int) (*repo.Category, error) {
+	return cached(ctx, r.cache, categoryCacheKey+strconv.Itoa(id), r.ttl, func() (*repo.Category, error) {
+		return r.CategoryStorageI.Get(ctx, id)
+	})
+}
+
+func (r *cachedCategoryRepo) Update(ctx context.Context, category *repo.Category) (*repo.Category, error) {
+	result, err := r.CategoryStorageI.Update(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+	return result, r.cache.inMemory.Delete(ctx, categoryCacheKey+strconv.Itoa(category.Id))
+}
+
+func (r *cachedCategoryRepo) Delete(ctx context.Context, id int) error {
+	if err := r.CategoryStorageI.Delete(ctx, id); err != nil {
+		return err
+	}
+	return r.cache.inMemory.Delete(ctx, categoryCacheKey+strconv.Itoa(id))
+}
+
+// The repos below change what a cached post holds: its tags and counters
+
+type cachedTagRepo struct {
+	repo.TagStorageI
+	cache *cache
+}
+
+func (r *cachedTagRepo) SetPostTags(ctx context.Context, postID int, names []string) ([]*repo.Tag, error) {
+	tags, err := r.TagStorageI.SetPostTags(ctx, postID, names)
+	if err != nil {
+		return nil, err
+	}
+	return tags, r.cache.dropPosts(ctx, postID)
+}
+
+type cachedLikeRepo struct {
+	repo.LikeStorageI
+	cache *cache
+}
+
+func (r *cachedLikeRepo) CreateOrUpdate(ctx context.Context, l *repo.Like) error {
+	if err := r.LikeStorageI.CreateOrUpdate(ctx, l); err != nil {
+		return err
+	}
+	return r.cache.dropPosts(ctx, int(l.PostID))
+}
+
+type cachedCommentRepo struct {
+	repo.CommentStorageI
+	cache *cache
+}
+
+func (r *cachedCommentRepo) Create(ctx context.Context, comment *repo.Comment) (*repo.Comment, error) {
+	result, err := r.CommentStorageI.Create(ctx, comment)
+	if err != nil {
+		return nil, err
+	}
+	return result, r.cache.dropPosts(ctx, comment.PostId)
+}
+
+func (r *cachedCommentRepo) Delete(ctx context.Context, id int) error {
+	comment, err := r.CommentStorageI.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := r.CommentStorageI.Delete(ctx, id); err != nil {
+		return err
+	}
+	return r.cache.dropPosts(ctx, comment.PostId)
+}
+
+type cachedClapRepo struct {
+	repo.ClapStorageI
+	cache *cache
+}
+
+func (r *cachedClapRepo) AddClaps(ctx context.Context, claps []*repo.Clap) error {
+	if err := r.ClapStorageI.AddClaps(ctx, claps); err != nil {
+		return err
+	}
+
+	ids := make([]int, 0, len(claps))
+	for _, c := range claps {
+		ids = append(ids, c.PostID)
+	}
+	return r.cache.dropPosts(ctx, ids...)
+}
+
+type cachedReadingListRepo struct {
+	repo.ReadingListStorageI
+	cache *cache
+}
+
+func (r *cachedReadingListRepo) AddPost(ctx context.Context, listID, postID int) error {
+	if err := r.ReadingListStorageI.AddPost(ctx, listID, postID); err != nil {
+		return err
+	}
+	return r.cache.dropPosts(ctx, postID)
+}
+
+func (r *cachedReadingListRepo) RemovePost(ctx context.Context, listID, postID int) error {
+	if err := r.ReadingListStorageI.RemovePost(ctx, listID, postID); err != nil {
+		return err
+	}
+	return r.cache.dropPosts(ctx, postID)
+}
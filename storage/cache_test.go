@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/post/config"
+	"github.com/post/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeInMemory struct {
+	InMemoryStorageI
+	values map[string]string
+	ttls   map[string]int
+}
+
+func (f *fakeInMemory) Get(ctx context.Context, key string) (string, error) {
+	val, ok := f.values[key]
+	if !ok {
+		return "", ErrKeyNotFound
+	}
+	return val, nil
+}
+
+func (f *fakeInMemory) MGet(ctx context.Context, keys ...string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, key := range keys {
+		if val, ok := f.values[key]; ok {
+			result[key] = val
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeInMemory) SetWithTTL(ctx context.Context, key, value string, n int) error {
+	f.values[key] = value
+	f.ttls[key] = n
+	return nil
+}
+
+func (f *fakeInMemory) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(f.values, key)
+	}
+	return nil
+}
+
+type fakePosts struct {
+	repo.PostStorageI
+	posts  map[int]*repo.Post
+	gets   int
+	loaded [][]int
+}
+
+func (f *fakePosts) Get(ctx context.Context, id int) (*repo.Post, error) {
+	f.gets++
+	p := *f.posts[id]
+	return &p, nil
+}
+
+func (f *fakePosts) GetMany(ctx context.Context, ids []int) (map[int]*repo.Post, error) {
+	f.loaded = append(f.loaded, ids)
+	result := make(map[int]*repo.Post)
+	for _, id := range ids {
+		if p, ok := f.posts[id]; ok {
+			post := *p
+			result[id] = &post
+		}
+	}
+	return result, nil
+}
+
+func (f *fakePosts) Update(ctx context.Context, p *repo.Post) (*repo.Post, error) {
+	f.posts[p.Id] = p
+	return p, nil
+}
+
+type fakeLikes struct {
+	repo.LikeStorageI
+	posts *fakePosts
+}
+
+func (f *fakeLikes) CreateOrUpdate(ctx context.Context, l *repo.Like) error {
+	f.posts.posts[int(l.PostID)].LikesCount++
+	return nil
+}
+
+type fakeStorage struct {
+	StorageI
+	posts *fakePosts
+}
+
+func (f *fakeStorage) Post() repo.PostStorageI {
+	return f.posts
+}
+
+func (f *fakeStorage) Like() repo.LikeStorageI {
+	return &fakeLikes{posts: f.posts}
+}
+
+func (f *fakeStorage) WithTx(ctx context.Context, fn func(StorageI) error) error {
+	return fn(f)
+}
+
+func (f *fakeStorage) User() repo.UserStorageI {
+	return nil
+}
+
+func (f *fakeStorage) Category() repo.CategoryStorageI {
+	return nil
+}
+
+func (f *fakeStorage) Tag() repo.TagStorageI {
+	return nil
+}
+
+func (f *fakeStorage) Comment() repo.CommentStorageI {
+	return nil
+}
+
+func (f *fakeStorage) Clap() repo.ClapStorageI {
+	return nil
+}
+
+func (f *fakeStorage) ReadingList() repo.ReadingListStorageI {
+	return nil
+}
+
+func newCachedFakes() (*fakePosts, *fakeInMemory, StorageI) {
+	posts := &fakePosts{posts: map[int]*repo.Post{
+		1: {Id: 1, Title: "cached", Tags: []string{"go"}},
+	}}
+	inMemory := &fakeInMemory{values: make(map[string]string), ttls: make(map[string]int)}
+	strg := NewCachedStorage(&fakeStorage{posts: posts}, inMemory, config.CacheConfig{
+		PostTTL: 5 * time.Minute,
+	})
+	return posts, inMemory, strg
+}
+
+func TestCachedPost(t *testing.T) {
+	ctx := context.Background()
+	posts, inMemory, strg := newCachedFakes()
+
+	for i := 0; i < 3; i++ {
+		post, err := strg.Post().Get(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, "cached", post.Title)
+		require.Equal(t, []string{"go"}, post.Tags)
+	}
+	require.Equal(t, 1, posts.gets)
+	require.Equal(t, 5, inMemory.ttls[postCacheKey+"1"])
+
+	_, err := strg.Post().Update(ctx, &repo.Post{Id: 1, Title: "updated"})
+	require.NoError(t, err)
+
+	post, err := strg.Post().Get(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, "updated", post.Title)
+	require.Equal(t, 2, posts.gets)
+}
+
+func TestCachedPostGetMany(t *testing.T) {
+	ctx := context.Background()
+	posts, inMemory, strg := newCachedFakes()
+	posts.posts[2] = &repo.Post{Id: 2, Title: "second"}
+
+	_, err := strg.Post().Get(ctx, 1)
+	require.NoError(t, err)
+
+	// only the posts missing from the cache are loaded, missing posts are
+	// left out
+	result, err := strg.Post().GetMany(ctx, []int{1, 2, 3})
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	require.Equal(t, "cached", result[1].Title)
+	require.Equal(t, "second", result[2].Title)
+	require.Equal(t, [][]int{{2, 3}}, posts.loaded)
+	require.Contains(t, inMemory.values, postCacheKey+"2")
+
+	result, err = strg.Post().GetMany(ctx, []int{1, 2})
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	require.Len(t, posts.loaded, 1)
+}
+
+func TestCachedPostCounters(t *testing.T) {
+	ctx := context.Background()
+	posts, _, strg := newCachedFakes()
+
+	_, err := strg.Post().Get(ctx, 1)
+	require.NoError(t, err)
+
+	require.NoError(t, strg.Like().CreateOrUpdate(ctx, &repo.Like{PostID: 1, UserID: 2, Status: true}))
+
+	post, err := strg.Post().Get(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), post.LikesCount)
+	require.Equal(t, 2, posts.gets)
+}
+
+func TestCachedWithTx(t *testing.T) {
+	ctx := context.Background()
+	posts, inMemory, strg := newCachedFakes()
+
+	_, err := strg.Post().Get(ctx, 1)
+	require.NoError(t, err)
+
+	err = strg.WithTx(context.Background(), func(tx StorageI) error {
+		_, err := tx.Post().Update(ctx, &repo.Post{Id: 1, Title: "updated"})
+		require.NoError(t, err)
+
+		// the transaction reads its own write, while the entry is kept
+		// for everyone else until it commits
+		post, err := tx.Post().Get(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, "updated", post.Title)
+		require.Contains(t, inMemory.values, postCacheKey+"1")
+		return nil
+	})
+	require.NoError(t, err)
+	require.NotContains(t, inMemory.values, postCacheKey+"1")
+	require.Equal(t, 2, posts.gets)
+}
+
+func TestCachedWithTxRollback(t *testing.T) {
+	ctx := context.Background()
+	_, inMemory, strg := newCachedFakes()
+
+	_, err := strg.Post().Get(ctx, 1)
+	require.NoError(t, err)
+
+	errAbort := errors.New("abort")
+	err = strg.WithTx(context.Background(), func(tx StorageI) error {
+		_, err := tx.Post().Update(ctx, &repo.Post{Id: 1, Title: "updated"})
+		require.NoError(t, err)
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+	require.Contains(t, inMemory.values, postCacheKey+"1")
+}
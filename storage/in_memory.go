@@ -8,10 +8,26 @@ import (
 )
 
 type InMemoryStorageI interface {
-	SetWithTTL(key string, value string, n int) error
-	Get(key string) (string, error)
+	SetWithTTL(ctx context.Context, key string, value string, n int) error
+	Get(ctx context.Context, key string) (string, error)
+	// MGet returns the values of the keys that exist
+	MGet(ctx context.Context, keys ...string) (map[string]string, error)
+	SetNX(ctx context.Context, key string, value string, ttl time.Duration) (bool, error)
+	// ExpireIfEqual sets the ttl of key if it holds value and reports whether it did
+	ExpireIfEqual(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+	// DeleteIfEqual deletes key if it holds value and reports whether it did
+	DeleteIfEqual(ctx context.Context, key, value string) (bool, error)
+	Delete(ctx context.Context, keys ...string) error
+	HIncrBy(ctx context.Context, key, field string, n int) (int, error)
+	HGet(ctx context.Context, key, field string) (string, error)
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	Rename(ctx context.Context, key, newKey string) (bool, error)
+	SAdd(ctx context.Context, key, member string, ttl time.Duration) (bool, error)
 }
 
+// ErrKeyNotFound is returned by Get and HGet for a missing key or field
+var ErrKeyNotFound = redis.Nil
+
 type storageRedis struct {
 	client *redis.Client
 }
@@ -22,18 +38,143 @@ func NewInMemoryStorage(rdb *redis.Client) InMemoryStorageI {
 	}
 }
 
-func (r *storageRedis) SetWithTTL(key string, value string, n int) error {
-	err := r.client.Set(context.Background(), key, value, time.Duration(n*int(time.Minute))).Err()
+func (r *storageRedis) SetWithTTL(ctx context.Context, key string, value string, n int) error {
+	err := r.client.Set(ctx, key, value, time.Duration(n*int(time.Minute))).Err()
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *storageRedis) Get(key string) (string, error) {
-	val, err := r.client.Get(context.Background(), key).Result()
+func (r *storageRedis) Get(ctx context.Context, key string) (string, error) {
+	val, err := r.client.Get(ctx, key).Result()
 	if err != nil {
 		return "", err
 	}
 	return val, nil
 }
+
+func (r *storageRedis) MGet(ctx context.Context, keys ...string) (map[string]string, error) {
+	result := make(map[string]string, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	vals, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, val := range vals {
+		if s, ok := val.(string); ok {
+			result[keys[i]] = s
+		}
+	}
+	return result, nil
+}
+
+func (r *storageRedis) SetNX(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	ok, err := r.client.SetNX(ctx, key, value, ttl).Result()
+	if err != nil {
+		return false, err
+	}
+	return ok, nil
+}
+
+// expireIfEqualScript and deleteIfEqualScript check the value of KEYS[1]
+// and change it in one step, so a lock is only kept or released by its owner
+var expireIfEqualScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+redis.call("PEXPIRE", KEYS[1], ARGV[2])
+return 1
+`)
+
+var deleteIfEqualScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+redis.call("DEL", KEYS[1])
+return 1
+`)
+
+func (r *storageRedis) ExpireIfEqual(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	ok, err := expireIfEqualScript.Run(ctx, r.client, []string{key}, value, ttl.Milliseconds()).Bool()
+	if err != nil {
+		return false, err
+	}
+	return ok, nil
+}
+
+func (r *storageRedis) DeleteIfEqual(ctx context.Context, key, value string) (bool, error) {
+	ok, err := deleteIfEqualScript.Run(ctx, r.client, []string{key}, value).Bool()
+	if err != nil {
+		return false, err
+	}
+	return ok, nil
+}
+
+func (r *storageRedis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.client.Del(ctx, keys...).Err()
+}
+
+func (r *storageRedis) HIncrBy(ctx context.Context, key, field string, n int) (int, error) {
+	val, err := r.client.HIncrBy(ctx, key, field, int64(n)).Result()
+	if err != nil {
+		return 0, err
+	}
+	return int(val), nil
+}
+
+func (r *storageRedis) HGet(ctx context.Context, key, field string) (string, error) {
+	val, err := r.client.HGet(ctx, key, field).Result()
+	if err != nil {
+		return "", err
+	}
+	return val, nil
+}
+
+func (r *storageRedis) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	val, err := r.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+// renameScript renames KEYS[1] to KEYS[2] if it exists, in one step so the
+// key can't expire or be renamed by another replica in between
+var renameScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+redis.call("RENAME", KEYS[1], KEYS[2])
+return 1
+`)
+
+// Rename moves key to newKey, replacing it. It returns false if key does not exist
+func (r *storageRedis) Rename(ctx context.Context, key, newKey string) (bool, error) {
+	renamed, err := renameScript.Run(ctx, r.client, []string{key, newKey}).Bool()
+	if err != nil {
+		return false, err
+	}
+	return renamed, nil
+}
+
+// SAdd adds member to the set and reports whether it was not there yet.
+// The set expires ttl after it is created
+func (r *storageRedis) SAdd(ctx context.Context, key, member string, ttl time.Duration) (bool, error) {
+	var added *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		added = pipe.SAdd(ctx, key, member)
+		pipe.ExpireNX(ctx, key, ttl)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return added.Val() == 1, nil
+}
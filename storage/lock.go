@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// WithLock runs fn while owner holds the lock key in inMemory. The lock is
+// taken for ttl and extended every third of it until fn returns, then
+// released if owner still holds it. fn's context is cancelled once the
+// lock can not be extended, so its work stops before another owner takes
+// over. WithLock reports false without running fn if the lock is held
+func WithLock(ctx context.Context, inMemory InMemoryStorageI, key, owner string, ttl time.Duration, fn func(ctx context.Context) error) (bool, error) {
+	ok, err := inMemory.SetNX(ctx, key, owner, ttl)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	lockCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-lockCtx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			held, err := inMemory.ExpireIfEqual(lockCtx, key, owner, ttl)
+			if err != nil || !held {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	err = fn(lockCtx)
+	cancel()
+	<-done
+
+	if _, releaseErr := inMemory.DeleteIfEqual(ctx, key, owner); err == nil {
+		err = releaseErr
+	}
+	return true, err
+}
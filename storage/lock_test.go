@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLocks struct {
+	InMemoryStorageI
+	mu      sync.Mutex
+	owners  map[string]string
+	extends int
+}
+
+func (f *fakeLocks) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.owners[key]; ok {
+		return false, nil
+	}
+	f.owners[key] = value
+	return true, nil
+}
+
+func (f *fakeLocks) ExpireIfEqual(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.extends++
+	return f.owners[key] == value, nil
+}
+
+func (f *fakeLocks) DeleteIfEqual(ctx context.Context, key, value string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.owners[key] != value {
+		return false, nil
+	}
+	delete(f.owners, key)
+	return true, nil
+}
+
+func (f *fakeLocks) setOwner(key, owner string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.owners[key] = owner
+}
+
+func TestWithLock(t *testing.T) {
+	ctx := context.Background()
+	locks := &fakeLocks{owners: map[string]string{"lock": "other"}}
+
+	ran := false
+	ok, err := WithLock(ctx, locks, "lock", "me", time.Second, func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.False(t, ran)
+
+	delete(locks.owners, "lock")
+	ok, err = WithLock(ctx, locks, "lock", "me", 30*time.Millisecond, func(ctx context.Context) error {
+		// the lock outlives its ttl while fn runs
+		time.Sleep(50 * time.Millisecond)
+		return ctx.Err()
+	})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NotZero(t, locks.extends)
+	require.NotContains(t, locks.owners, "lock")
+}
+
+func TestWithLockLost(t *testing.T) {
+	ctx := context.Background()
+	locks := &fakeLocks{owners: make(map[string]string)}
+
+	ok, err := WithLock(ctx, locks, "lock", "me", 30*time.Millisecond, func(ctx context.Context) error {
+		locks.setOwner("lock", "other")
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.True(t, ok)
+
+	// the lock of the new owner is kept
+	require.Equal(t, "other", locks.owners["lock"])
+}
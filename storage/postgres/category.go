@@ -1,30 +1,29 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
 
-	"github.com/jmoiron/sqlx"
 	"github.com/post/storage/repo"
 )
 
 type categoryRepo struct {
-	db *sqlx.DB
+	db DB
 }
 
-func NewCategory(db *sqlx.DB) repo.CategoryStorageI {
+func NewCategory(db DB) repo.CategoryStorageI {
 	return &categoryRepo{
 		db: db,
 	}
 }
 
-func (cr *categoryRepo) Create(category *repo.Category) (*repo.Category, error) {
+func (cr *categoryRepo) Create(ctx context.Context, category *repo.Category) (*repo.Category, error) {
 	query := `
 		INSERT INTO categories(title) VALUES($1)
 		RETURNING id, created_at
 	`
 
-	row := cr.db.QueryRow(
+	row := cr.db.QueryRowContext(ctx,
 		query,
 		category.Title,
 	)
@@ -40,7 +39,7 @@ func (cr *categoryRepo) Create(category *repo.Category) (*repo.Category, error)
 	return category, nil
 }
 
-func (cr *categoryRepo) Get(id int) (*repo.Category, error) {
+func (cr *categoryRepo) Get(ctx context.Context, id int) (*repo.Category, error) {
 	var result repo.Category
 
 	query := `
@@ -52,7 +51,7 @@ func (cr *categoryRepo) Get(id int) (*repo.Category, error) {
 		WHERE id=$1
 	`
 
-	row := cr.db.QueryRow(query, id)
+	row := cr.db.QueryRowContext(ctx, query, id)
 	err := row.Scan(
 		&result.Id,
 		&result.Title,
@@ -65,19 +64,17 @@ func (cr *categoryRepo) Get(id int) (*repo.Category, error) {
 	return &result, nil
 }
 
-func (cr *categoryRepo) GetAll(param repo.GetCategoryQuery) (*repo.GetAllCategoriesResult, error) {
+func (cr *categoryRepo) GetAll(ctx context.Context, param repo.GetCategoryQuery) (*repo.GetAllCategoriesResult, error) {
 	result := repo.GetAllCategoriesResult{
 		Categories: make([]*repo.Category, 0),
 	}
 
-	offset := (param.Page - 1) * param.Limit
-
-	limit := fmt.Sprintf(" LIMIT %d OFFSET %d ", param.Limit, offset)
-	filter := ""
+	f := filter{}
 	if param.Search != "" {
-		str := "%" + param.Search + "%"
-		filter += fmt.Sprintf(` where title ILIKE '%s'`, str)
+		f.and("categories.title ILIKE ?", containsPattern(param.Search))
 	}
+	f.after("categories", "desc", param.After)
+	countArgs := f.countArgs()
 
 	query := `
 		SELECT 
@@ -85,11 +82,11 @@ func (cr *categoryRepo) GetAll(param repo.GetCategoryQuery) (*repo.GetAllCategor
 			title,
 			created_at
 		FROM categories
-		` + filter + `
-		ORDER BY created_at desc
-		` + limit
+		` + f.where() + `
+		ORDER BY ` + byCreation("categories", "desc") + `
+		` + f.page(param.Page, param.Limit)
 
-	rows, err := cr.db.Query(query)
+	rows, err := cr.db.QueryContext(ctx, query, f.args...)
 	if err != nil {
 		return nil, err
 	}
@@ -106,23 +103,29 @@ func (cr *categoryRepo) GetAll(param repo.GetCategoryQuery) (*repo.GetAllCategor
 		}
 		result.Categories = append(result.Categories, &Categ)
 	}
-	queryCount := `SELECT count(1) FROM categories ` + filter
-	err = cr.db.QueryRow(queryCount).Scan(&result.Count)
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if param.SkipCount {
+		return &result, nil
+	}
+
+	queryCount := `SELECT count(1) FROM categories` + f.where()
+	err = cr.db.QueryRowContext(ctx, queryCount, countArgs...).Scan(&result.Count)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Println(result)
 	return &result, nil
 }
 
-func (cr *categoryRepo) Update(category *repo.Category) (*repo.Category, error) {
+func (cr *categoryRepo) Update(ctx context.Context, category *repo.Category) (*repo.Category, error) {
 	query := `
 		update categories set
 			title=$1
 		where id=$2
 		returning created_at
 	`
-	row := cr.db.QueryRow(query, category.Title, category.Id)
+	row := cr.db.QueryRowContext(ctx, query, category.Title, category.Id)
 	if err := row.Scan(&category.CreatedAt); err != nil {
 		return nil, err
 	}
@@ -130,8 +133,8 @@ func (cr *categoryRepo) Update(category *repo.Category) (*repo.Category, error)
 	return category, nil
 }
 
-func (ur *categoryRepo) Delete(id int) error {
-	res, err := ur.db.Exec("delete from categories where id=$1", id)
+func (ur *categoryRepo) Delete(ctx context.Context, id int) error {
+	res, err := ur.db.ExecContext(ctx, "delete from categories where id=$1", id)
 	if err != nil {
 		return err
 	}
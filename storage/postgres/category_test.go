@@ -9,7 +9,7 @@ import (
 )
 
 func createCategory(t *testing.T) *repo.Category {
-	Category, err := strg.Category().Create(&repo.Category{
+	Category, err := strg.Category().Create(ctx, &repo.Category{
 		Title: faker.Sentence(),
 	})
 	require.NoError(t, err)
@@ -18,13 +18,13 @@ func createCategory(t *testing.T) *repo.Category {
 }
 
 func deleteCategory(id int, t *testing.T) {
-	err := strg.Category().Delete(id)
+	err := strg.Category().Delete(ctx, id)
 	require.NoError(t, err)
 }
 
 func TestGetCategory(t *testing.T) {
 	n := createCategory(t)
-	note, err := strg.Category().Get(n.Id)
+	note, err := strg.Category().Get(ctx, n.Id)
 	require.NoError(t, err)
 	require.NotEmpty(t, note)
 	deleteCategory(note.Id, t)
@@ -39,7 +39,7 @@ func TestUpdateCategory(t *testing.T) {
 
 	n.Title = faker.Sentence()
 
-	Category, err := strg.Category().Update(n)
+	Category, err := strg.Category().Update(ctx, n)
 	require.NoError(t, err)
 	require.NotEmpty(t, Category)
 
@@ -54,7 +54,7 @@ func TestDeleteCategory(t *testing.T) {
 func TestGetAllCategory(t *testing.T) {
 	u := createCategory(t)
 	n, _ := faker.RandomInt(100)
-	_, err := strg.Category().GetAll(repo.GetCategoryQuery{
+	_, err := strg.Category().GetAll(ctx, repo.GetCategoryQuery{
 		Page:  n[0],
 		Limit: n[1],
 	})
@@ -62,3 +62,34 @@ func TestGetAllCategory(t *testing.T) {
 	require.NoError(t, err)
 	deleteCategory(u.Id, t)
 }
+
+func TestGetAllCategoryCursor(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		c := createCategory(t)
+		defer deleteCategory(c.Id, t)
+	}
+
+	first, err := strg.Category().GetAll(ctx, repo.GetCategoryQuery{
+		Page:      1,
+		Limit:     2,
+		SkipCount: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, first.Categories, 2)
+	require.Zero(t, first.Count)
+
+	last := first.Categories[1]
+	next, err := strg.Category().GetAll(ctx, repo.GetCategoryQuery{
+		Page:  1,
+		Limit: 2,
+		After: &repo.Cursor{CreatedAt: last.CreatedAt, Id: last.Id},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, next.Categories)
+	require.NotZero(t, next.Count)
+	for _, c := range next.Categories {
+		require.NotEqual(t, first.Categories[0].Id, c.Id)
+		require.NotEqual(t, last.Id, c.Id)
+		require.False(t, c.CreatedAt.After(last.CreatedAt))
+	}
+}
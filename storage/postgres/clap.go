@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/post/storage/repo"
+)
+
+type clapRepo struct {
+	db DB
+}
+
+func NewClap(db DB) repo.ClapStorageI {
+	return &clapRepo{
+		db: db,
+	}
+}
+
+func (cr *clapRepo) AddClaps(ctx context.Context, claps []*repo.Clap) error {
+	if len(claps) == 0 {
+		return nil
+	}
+
+	postIDs := make([]int64, 0, len(claps))
+	userIDs := make([]int64, 0, len(claps))
+	counts := make([]int64, 0, len(claps))
+	for _, c := range claps {
+		postIDs = append(postIDs, int64(c.PostID))
+		userIDs = append(userIDs, int64(c.UserID))
+		counts = append(counts, int64(c.Count))
+	}
+
+	// Claps of posts or users removed in the meantime are dropped
+	query := `
+		INSERT INTO claps(post_id, user_id, count)
+		SELECT t.post_id, t.user_id, LEAST(t.count, $4)
+		FROM unnest($1::int[], $2::int[], $3::int[]) AS t(post_id, user_id, count)
+		WHERE t.count > 0
+			AND EXISTS(SELECT 1 FROM posts p WHERE p.id=t.post_id)
+			AND EXISTS(SELECT 1 FROM users u WHERE u.id=t.user_id)
+		ON CONFLICT (post_id, user_id) DO UPDATE SET
+			count=LEAST(claps.count + EXCLUDED.count, $4),
+			updated_at=current_timestamp
+	`
+	_, err := cr.db.ExecContext(ctx,
+		query,
+		pq.Array(postIDs),
+		pq.Array(userIDs),
+		pq.Array(counts),
+		repo.MaxClapsPerUser,
+	)
+	return err
+}
+
+func (cr *clapRepo) Get(ctx context.Context, postID, userID int) (int, error) {
+	var count int
+
+	query := `SELECT count FROM claps WHERE post_id=$1 AND user_id=$2`
+	err := cr.db.QueryRowContext(ctx, query, postID, userID).Scan(&count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (cr *clapRepo) GetTotal(ctx context.Context, postID int) (int, error) {
+	var total int
+
+	query := `SELECT coalesce(sum(count), 0) FROM claps WHERE post_id=$1`
+	if err := cr.db.QueryRowContext(ctx, query, postID).Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (cr *clapRepo) GetClappers(ctx context.Context, param repo.GetClappersQuery) (*repo.GetAllClappersResult, error) {
+	result := repo.GetAllClappersResult{
+		Clappers: make([]*repo.Clapper, 0),
+	}
+
+	f := filter{}
+	f.and("c.post_id=?", param.PostID)
+	countArgs := f.countArgs()
+
+	query := `
+		SELECT
+			u.id,
+			u.first_name,
+			coalesce(u.last_name, ''),
+			u.email,
+			u.profile_image_url,
+			c.count
+		FROM claps c
+		INNER JOIN users u ON u.id=c.user_id
+		` + f.where() + `
+		ORDER BY c.count desc, c.updated_at desc` + f.page(param.Page, param.Limit)
+
+	rows, err := cr.db.QueryContext(ctx, query, f.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var c repo.Clapper
+		if err := rows.Scan(
+			&c.User.Id,
+			&c.User.FirstName,
+			&c.User.LastName,
+			&c.User.Email,
+			&c.User.ProfileImageUrl,
+			&c.Count,
+		); err != nil {
+			return nil, err
+		}
+		result.Clappers = append(result.Clappers, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	queryCount := `SELECT count(1) FROM claps c` + f.where()
+	err = cr.db.QueryRowContext(ctx, queryCount, countArgs...).Scan(&result.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,40 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/post/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaps(t *testing.T) {
+	post := createPost(t)
+	defer deletePost(post.Id, t)
+
+	err := strg.Clap().AddClaps(ctx, []*repo.Clap{
+		{PostID: post.Id, UserID: 1, Count: 30},
+	})
+	require.NoError(t, err)
+
+	err = strg.Clap().AddClaps(ctx, []*repo.Clap{
+		{PostID: post.Id, UserID: 1, Count: 30},
+	})
+	require.NoError(t, err)
+
+	count, err := strg.Clap().Get(ctx, post.Id, 1)
+	require.NoError(t, err)
+	require.Equal(t, repo.MaxClapsPerUser, count)
+
+	total, err := strg.Clap().GetTotal(ctx, post.Id)
+	require.NoError(t, err)
+	require.Equal(t, repo.MaxClapsPerUser, total)
+
+	clappers, err := strg.Clap().GetClappers(ctx, repo.GetClappersQuery{
+		PostID: post.Id,
+		Page:   1,
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, clappers.Count)
+	require.Equal(t, 1, clappers.Clappers[0].User.Id)
+}
@@ -1,197 +1,300 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
+	"errors"
 	"time"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/post/storage/repo"
 )
 
 type commentRepo struct {
-	db *sqlx.DB
+	db DB
 }
 
-func NewComment(db *sqlx.DB) repo.CommentStorageI {
+func NewComment(db DB) repo.CommentStorageI {
 	return &commentRepo{db: db}
 }
 
-func (cr *commentRepo) Create(comment *repo.Comment) (*repo.Comment, error) {
-	query := `
-		INSERT INTO comments(
-			post_id,
-			user_id,
-			description
-		) values ($1,$2,$3)
-		RETURNING
-			id,
-			created_at
-	`
-	result := cr.db.QueryRow(
-		query,
-		comment.PostId,
-		comment.UserId,
-		comment.Description,
-	)
-	if err := result.Scan(
-		&comment.Id,
-		&comment.CreatedAt,
-	); err != nil {
+func (cr *commentRepo) Create(ctx context.Context, comment *repo.Comment) (*repo.Comment, error) {
+	err := inTx(ctx, cr.db, func(tx *sqlx.Tx) error {
+		query := `
+			INSERT INTO comments(
+				post_id,
+				user_id,
+				description,
+				parent_id
+			) values ($1,$2,$3,$4)
+			RETURNING
+				id,
+				created_at
+		`
+		result := tx.QueryRowContext(ctx,
+			query,
+			comment.PostId,
+			comment.UserId,
+			comment.Description,
+			comment.ParentId,
+		)
+		if err := result.Scan(
+			&comment.Id,
+			&comment.CreatedAt,
+		); err != nil {
+			return err
+		}
+
+		return addPostCounter(ctx, tx, int64(comment.PostId), postCommentsCount, 1)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return comment, nil
 }
 
-func (cr *commentRepo) Get(id int) (*repo.Comment, error) {
-	var Comment repo.Comment
-	query := `
-		SELECT
+// commentColumns are read by scanComment. Tombstoned comments keep
+// their row so the replies stay attached, but lose the text
+const commentColumns = `
 			c.id,
-			c.user_id,
 			c.post_id,
-			c.description,
+			c.user_id,
+			CASE WHEN c.deleted_at IS NULL THEN c.description ELSE '' END,
 			c.created_at,
+			coalesce(c.updated_at, c.created_at),
+			c.parent_id,
+			(SELECT count(1) FROM comments r WHERE r.parent_id=c.id),
+			c.deleted_at IS NOT NULL,
 			u.first_name,
-			u.last_name,
+			coalesce(u.last_name, ''),
 			u.email,
 			u.profile_image_url
-		FROM comments c
-		INNER JOIN users u ON u.id=c.user_id
-		where c.id=$1`
+`
 
-	result := cr.db.QueryRow(
-		query,
-		id,
-	)
-	if err := result.Scan(
-		&Comment.Id,
-		&Comment.PostId,
-		&Comment.UserId,
-		&Comment.Description,
-		&Comment.CreatedAt,
-		&Comment.User.FirstName,
-		&Comment.User.LastName,
-		&Comment.User.Email,
-		&Comment.User.ProfileImageUrl,
+func scanComment(row interface{ Scan(...interface{}) error }) (*repo.Comment, error) {
+	var c repo.Comment
+	if err := row.Scan(
+		&c.Id,
+		&c.PostId,
+		&c.UserId,
+		&c.Description,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+		&c.ParentId,
+		&c.ReplyCount,
+		&c.Deleted,
+		&c.User.FirstName,
+		&c.User.LastName,
+		&c.User.Email,
+		&c.User.ProfileImageUrl,
 	); err != nil {
 		return nil, err
 	}
+	c.User.Id = c.UserId
+
+	return &c, nil
+}
+
+func (cr *commentRepo) Get(ctx context.Context, id int) (*repo.Comment, error) {
+	query := `
+		SELECT` + commentColumns + `
+		FROM comments c
+		INNER JOIN users u ON u.id=c.user_id
+		where c.id=$1`
 
-	return &Comment, nil
+	return scanComment(cr.db.QueryRowContext(ctx, query, id))
 }
 
-func (cr *commentRepo) GetAll(param repo.GetCommentQuery) (*repo.GetAllCommentsResult, error) {
+func (cr *commentRepo) GetAll(ctx context.Context, param repo.GetCommentQuery) (*repo.GetAllCommentsResult, error) {
 	result := repo.GetAllCommentsResult{
 		Comments: make([]*repo.Comment, 0),
 	}
 
-	offset := (param.Page - 1) * param.Limit
+	// comments are listed oldest first unless asked otherwise
+	dir := sortDirection(param.SortByDate, "asc")
 
-	limit := fmt.Sprintf(" LIMIT %d OFFSET %d ", param.Limit, offset)
-	filter := ""
+	f := filter{}
 	if param.PostId > 0 {
-		filter += fmt.Sprintf("where post_id=%d", param.PostId)
+		f.and("c.post_id=?", param.PostId)
 	}
-
 	if param.UserId > 0 {
-		if filter == "" {
-			filter += fmt.Sprintf("where user_id=%d", param.UserId)
-		} else {
-			filter += fmt.Sprintf("and user_id=%d", param.UserId)
-		}
+		f.and("c.user_id=?", param.UserId)
+	}
+	if param.ParentId > 0 {
+		f.and("c.parent_id=?", param.ParentId)
+	} else if param.RootsOnly {
+		f.and("c.parent_id IS NULL")
+	}
+	f.after("c", dir, param.After)
+	countArgs := f.countArgs()
+
+	orderBy := byCreation("c", dir)
+	if param.Sort == repo.CommentSortTop {
+		orderBy = "(SELECT count(1) FROM comment_reactions rc WHERE rc.comment_id=c.id) desc, c.created_at desc"
 	}
 
 	query := `
-		SELECT
-			c.id,
-			c.user_id,
-			c.post_id,
-			c.description,
-			c.created_at,
-			u.first_name,
-			u.last_name,
-			u.email,
-			u.profile_image_url
+		SELECT` + commentColumns + `
 		FROM comments c
 		INNER JOIN users u ON u.id=c.user_id
-		` + filter + `
-		ORDER BY c.created_at ` + param.SortByDate + ` ` + limit
+		` + f.where() + `
+		ORDER BY ` + orderBy + f.page(param.Page, param.Limit)
 
-	rows, err := cr.db.Query(query)
+	rows, err := cr.db.QueryContext(ctx, query, f.args...)
 	if err != nil {
 		return nil, err
 	}
 
 	defer rows.Close()
 	for rows.Next() {
-		var Comment repo.Comment
-		if err := rows.Scan(
-			&Comment.Id,
-			&Comment.PostId,
-			&Comment.UserId,
-			&Comment.Description,
-			&Comment.CreatedAt,
-			&Comment.User.FirstName,
-			&Comment.User.LastName,
-			&Comment.User.Email,
-			&Comment.User.ProfileImageUrl,
-		); err != nil {
+		Comment, err := scanComment(rows)
+		if err != nil {
 			return nil, err
 		}
-		result.Comments = append(result.Comments, &Comment)
+		result.Comments = append(result.Comments, Comment)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if param.SkipCount {
+		return &result, nil
+	}
+
 	queryCount := `
 		SELECT count(1) FROM comments c
-		INNER JOIN users u ON u.id=c.user_id ` + filter
-	err = cr.db.QueryRow(queryCount).Scan(&result.Count)
+		INNER JOIN users u ON u.id=c.user_id` + f.where()
+	err = cr.db.QueryRowContext(ctx, queryCount, countArgs...).Scan(&result.Count)
 	if err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
-func (cr *commentRepo) Update(comme *repo.Comment) (*repo.Comment, error) {
-	result := cr.db.QueryRow(`
+func (cr *commentRepo) Update(ctx context.Context, comme *repo.Comment) (*repo.Comment, error) {
+	result := cr.db.QueryRowContext(ctx, `
 		update comments set 
 			description=$1,
 			updated_at=$2
-		where id=$3
-		RETURNING created_at
+		where id=$3 AND deleted_at IS NULL
+		RETURNING post_id, user_id, parent_id, created_at, updated_at
 	`,
 		comme.Description,
 		time.Now(),
 		comme.Id,
 	)
 
-	comme.UpdatedAt = time.Now()
 	if err := result.Scan(
+		&comme.PostId,
+		&comme.UserId,
+		&comme.ParentId,
 		&comme.CreatedAt,
+		&comme.UpdatedAt,
 	); err != nil {
 		return nil, err
 	}
-	fmt.Println(comme)
 
 	return comme, nil
 }
 
-func (cr *commentRepo) Delete(id int) error {
-	res, err := cr.db.Exec("delete from comments where id=$1", id)
-	if err != nil {
-		return err
+// Delete removes the comment. A comment with replies is tombstoned
+// instead, and tombstones left without replies are removed as well
+func (cr *commentRepo) Delete(ctx context.Context, id int) error {
+	return inTx(ctx, cr.db, func(tx *sqlx.Tx) error {
+		var postID int64
+
+		err := tx.QueryRowContext(ctx, `
+			UPDATE comments SET deleted_at=now()
+			WHERE id=$1 AND deleted_at IS NULL
+				AND EXISTS(SELECT 1 FROM comments r WHERE r.parent_id=$1)
+			RETURNING post_id
+		`, id).Scan(&postID)
+		if err == nil {
+			return addPostCounter(ctx, tx, postID, postCommentsCount, -1)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		var (
+			parentID *int
+			live     bool
+		)
+		err = tx.QueryRowContext(ctx, `
+			DELETE FROM comments c
+			WHERE c.id=$1 AND NOT EXISTS(SELECT 1 FROM comments r WHERE r.parent_id=c.id)
+			RETURNING c.post_id, c.parent_id, c.deleted_at IS NULL
+		`, id).Scan(&postID, &parentID, &live)
+		if err != nil {
+			return err
+		}
+		if live {
+			if err := addPostCounter(ctx, tx, postID, postCommentsCount, -1); err != nil {
+				return err
+			}
+		}
+
+		// Tombstones are not counted, pruning them leaves the counter as is
+		for parentID != nil {
+			err = tx.QueryRowContext(ctx, `
+				DELETE FROM comments c
+				WHERE c.id=$1 AND c.deleted_at IS NOT NULL
+					AND NOT EXISTS(SELECT 1 FROM comments r WHERE r.parent_id=c.id)
+				RETURNING c.parent_id
+			`, *parentID).Scan(&parentID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetDescendants returns the replies under the root comments down to depth
+// levels, ordered so that parents come before their replies
+func (cr *commentRepo) GetDescendants(ctx context.Context, rootIDs []int, depth int) ([]*repo.Comment, error) {
+	result := make([]*repo.Comment, 0)
+	if len(rootIDs) == 0 || depth <= 0 {
+		return result, nil
 	}
-	rows, err := res.RowsAffected()
+
+	query := `
+		WITH RECURSIVE tree AS (
+			SELECT id, 1 AS depth FROM comments WHERE parent_id = ANY($1)
+			UNION ALL
+			SELECT c.id, t.depth + 1 FROM comments c
+			INNER JOIN tree t ON c.parent_id=t.id
+			WHERE t.depth < $2
+		)
+		SELECT` + commentColumns + `
+		FROM tree t
+		INNER JOIN comments c ON c.id=t.id
+		INNER JOIN users u ON u.id=c.user_id
+		ORDER BY t.depth, c.created_at
+	`
+
+	rows, err := cr.db.QueryContext(ctx, query, pq.Array(rootIDs), depth)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if rows == 0 {
-		return sql.ErrNoRows
+
+	defer rows.Close()
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, comment)
 	}
-	return nil
+
+	return result, rows.Err()
 }
 
-func (cr *commentRepo) GetUserInfo(id int) int {
+func (cr *commentRepo) GetUserInfo(ctx context.Context, id int) int {
 	var userId int
 
 	query := `
@@ -200,7 +303,7 @@ func (cr *commentRepo) GetUserInfo(id int) int {
 		from comments
 		where id=$1
 	`
-	row := cr.db.QueryRow(query, id)
+	row := cr.db.QueryRowContext(ctx, query, id)
 	if err := row.Scan(
 		&userId,
 	); err != nil {
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/post/storage/repo"
+)
+
+type commentReactionRepo struct {
+	db DB
+}
+
+func NewCommentReaction(db DB) repo.CommentReactionStorageI {
+	return &commentReactionRepo{
+		db: db,
+	}
+}
+
+// CreateOrUpdate toggles the reaction: the same reaction twice removes
+// it, a different one replaces the previous reaction
+func (rr *commentReactionRepo) CreateOrUpdate(ctx context.Context, r *repo.CommentReaction) error {
+	reaction, err := rr.Get(ctx, r.UserID, r.CommentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		query := `
+			INSERT INTO comment_reactions(comment_id, user_id, reaction)
+			VALUES($1, $2, $3)
+		`
+
+		_, err := rr.db.ExecContext(ctx, query, r.CommentID, r.UserID, r.Reaction)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if reaction.Reaction == r.Reaction {
+		query := `DELETE FROM comment_reactions WHERE comment_id=$1 AND user_id=$2`
+		_, err := rr.db.ExecContext(ctx, query, r.CommentID, r.UserID)
+		return err
+	}
+
+	query := `UPDATE comment_reactions SET reaction=$1 WHERE comment_id=$2 AND user_id=$3`
+	_, err = rr.db.ExecContext(ctx, query, r.Reaction, r.CommentID, r.UserID)
+	return err
+}
+
+func (rr *commentReactionRepo) Get(ctx context.Context, userID, commentID int) (*repo.CommentReaction, error) {
+	var result repo.CommentReaction
+
+	query := `
+		SELECT
+			comment_id,
+			user_id,
+			reaction
+		FROM comment_reactions
+		WHERE user_id=$1 AND comment_id=$2
+	`
+
+	err := rr.db.QueryRowContext(ctx, query, userID, commentID).Scan(
+		&result.CommentID,
+		&result.UserID,
+		&result.Reaction,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetReactions counts the reactions of all the comments in one query.
+// Comments without reactions are left out of the result
+func (rr *commentReactionRepo) GetReactions(ctx context.Context, commentIDs []int, viewerID int) (map[int]*repo.CommentReactions, error) {
+	result := make(map[int]*repo.CommentReactions)
+	if len(commentIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT
+			comment_id,
+			reaction,
+			count(1),
+			bool_or(user_id=$2)
+		FROM comment_reactions
+		WHERE comment_id=ANY($1)
+		GROUP BY comment_id, reaction
+	`
+
+	rows, err := rr.db.QueryContext(ctx, query, pq.Array(commentIDs), viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			commentID, count int
+			reaction         string
+			mine             bool
+		)
+		if err := rows.Scan(&commentID, &reaction, &count, &mine); err != nil {
+			return nil, err
+		}
+
+		r, ok := result[commentID]
+		if !ok {
+			r = &repo.CommentReactions{
+				Counts: make(map[string]int),
+			}
+			result[commentID] = r
+		}
+		r.Counts[reaction] = count
+		if mine {
+			r.MyReaction = reaction
+		}
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,38 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/post/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommentReaction(t *testing.T) {
+	comment := createComment(t)
+	defer deleteComment(comment.Id, t)
+
+	react := func(reaction string) {
+		err := strg.CommentReaction().CreateOrUpdate(ctx, &repo.CommentReaction{
+			CommentID: comment.Id,
+			UserID:    1,
+			Reaction:  reaction,
+		})
+		require.NoError(t, err)
+	}
+
+	react(repo.ReactionLike)
+	reactions, err := strg.CommentReaction().GetReactions(ctx, []int{comment.Id}, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, reactions[comment.Id].Counts[repo.ReactionLike])
+	require.Equal(t, repo.ReactionLike, reactions[comment.Id].MyReaction)
+
+	react(repo.ReactionLaugh)
+	reactions, err = strg.CommentReaction().GetReactions(ctx, []int{comment.Id}, 1)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{repo.ReactionLaugh: 1}, reactions[comment.Id].Counts)
+
+	react(repo.ReactionLaugh)
+	reactions, err = strg.CommentReaction().GetReactions(ctx, []int{comment.Id}, 1)
+	require.NoError(t, err)
+	require.NotContains(t, reactions, comment.Id)
+}
@@ -1,6 +1,7 @@
 package postgres_test
 
 import (
+	"database/sql"
 	"testing"
 
 	"github.com/bxcodec/faker/v4"
@@ -9,7 +10,7 @@ import (
 )
 
 func createComment(t *testing.T) *repo.Comment {
-	Comment, err := strg.Comment().Create(&repo.Comment{
+	Comment, err := strg.Comment().Create(ctx, &repo.Comment{
 		PostId:      1,
 		UserId:      1,
 		Description: faker.Sentence(),
@@ -20,13 +21,13 @@ func createComment(t *testing.T) *repo.Comment {
 }
 
 func deleteComment(id int, t *testing.T) {
-	err := strg.Comment().Delete(id)
+	err := strg.Comment().Delete(ctx, id)
 	require.NoError(t, err)
 }
 
 func TestGetComment(t *testing.T) {
 	n := createComment(t)
-	note, err := strg.Comment().Get(n.Id)
+	note, err := strg.Comment().Get(ctx, n.Id)
 	require.NoError(t, err)
 	require.NotEmpty(t, note)
 
@@ -40,13 +41,14 @@ func TestCreateComment(t *testing.T) {
 func TestUpdateComment(t *testing.T) {
 	n := createComment(t)
 	
-	n.PostId = 1
-	n.UserId = 1
-	n.Description = faker.Sentence()
-
-	Comment, err := strg.Comment().Update(n)
+	Comment, err := strg.Comment().Update(ctx, &repo.Comment{
+		Id:          n.Id,
+		Description: faker.Sentence(),
+	})
 	require.NoError(t, err)
 	require.NotEmpty(t, Comment)
+	require.Equal(t, n.UserId, Comment.UserId)
+	require.Equal(t, n.PostId, Comment.PostId)
 
 	deleteComment(Comment.Id, t)
 }
@@ -59,7 +61,7 @@ func TestDeleteComment(t *testing.T) {
 func TestGetAllComment(t *testing.T) {
 	u := createComment(t)
 	n, _ := faker.RandomInt(100)
-	_, err := strg.Comment().GetAll(repo.GetCommentQuery{
+	_, err := strg.Comment().GetAll(ctx, repo.GetCommentQuery{
 		Page:  n[0],
 		Limit: n[0],
 	})
@@ -67,3 +69,34 @@ func TestGetAllComment(t *testing.T) {
 	require.NoError(t, err)
 	deleteComment(u.Id, t)
 }
+
+func TestCommentReplies(t *testing.T) {
+	parent := createComment(t)
+
+	reply, err := strg.Comment().Create(ctx, &repo.Comment{
+		PostId:      parent.PostId,
+		UserId:      1,
+		Description: faker.Sentence(),
+		ParentId:    &parent.Id,
+	})
+	require.NoError(t, err)
+
+	got, err := strg.Comment().Get(ctx, parent.Id)
+	require.NoError(t, err)
+	require.Equal(t, 1, got.ReplyCount)
+
+	replies, err := strg.Comment().GetDescendants(ctx, []int{parent.Id}, 1)
+	require.NoError(t, err)
+	require.Len(t, replies, 1)
+	require.Equal(t, reply.Id, replies[0].Id)
+
+	deleteComment(parent.Id, t)
+	got, err = strg.Comment().Get(ctx, parent.Id)
+	require.NoError(t, err)
+	require.True(t, got.Deleted)
+	require.Empty(t, got.Description)
+
+	deleteComment(reply.Id, t)
+	_, err = strg.Comment().Get(ctx, parent.Id)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
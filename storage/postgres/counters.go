@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Denormalized counters of posts. They are changed in the same transaction
+// as the rows they count, and rebuilt by postRepo.RecountCounters
+const (
+	postLikesCount     = "likes_count"
+	postDislikesCount  = "dislikes_count"
+	postCommentsCount  = "comments_count"
+	postBookmarksCount = "bookmarks_count"
+)
+
+// addPostCounter adds n to one of the counter columns of the post,
+// and to today's stats of the post if they track the same thing
+func addPostCounter(ctx context.Context, tx *sqlx.Tx, postID int64, column string, n int) error {
+	if n == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`UPDATE posts SET %s=%s+$1 WHERE id=$2`, column, column)
+	if _, err := tx.ExecContext(ctx, query, n, postID); err != nil {
+		return err
+	}
+
+	if stat, ok := dailyStatColumns[column]; ok {
+		return addDailyStat(ctx, tx, postID, stat, n)
+	}
+	return nil
+}
+
+func (pr *postRepo) RecountCounters(ctx context.Context) (int64, error) {
+	query := `
+		WITH counts AS (
+			SELECT
+				p.id,
+				(SELECT count(1) FROM likes l WHERE l.post_id=p.id AND l.status) AS likes,
+				(SELECT count(1) FROM likes l WHERE l.post_id=p.id AND NOT l.status) AS dislikes,
+				(SELECT count(1) FROM comments c WHERE c.post_id=p.id AND c.deleted_at IS NULL) AS comments,
+				(SELECT count(1) FROM reading_list_items i WHERE i.post_id=p.id) AS bookmarks
+			FROM posts p
+		)
+		UPDATE posts SET
+			likes_count=c.likes,
+			dislikes_count=c.dislikes,
+			comments_count=c.comments,
+			bookmarks_count=c.bookmarks
+		FROM counts c
+		WHERE posts.id=c.id AND (
+			posts.likes_count,
+			posts.dislikes_count,
+			posts.comments_count,
+			posts.bookmarks_count
+		) <> (c.likes, c.dislikes, c.comments, c.bookmarks)
+	`
+
+	res, err := pr.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DB runs the queries of the repos. It is the connection pool, or a
+// transaction for repos bound to one
+type DB interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// RunTx runs fn in a transaction of db. The transaction is committed if fn
+// succeeds and rolled back if it fails or panics
+func RunTx(ctx context.Context, db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// inTx runs fn in a transaction of its own, or in the one db is already
+// bound to, which is then committed by its owner
+func inTx(ctx context.Context, db DB, fn func(tx *sqlx.Tx) error) error {
+	if tx, ok := db.(*sqlx.Tx); ok {
+		return fn(tx)
+	}
+	return RunTx(ctx, db.(*sqlx.DB), fn)
+}
@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/post/storage/repo"
+)
+
+type followRepo struct {
+	db DB
+}
+
+func NewFollow(db DB) repo.FollowStorageI {
+	return &followRepo{
+		db: db,
+	}
+}
+
+func (fr *followRepo) Follow(ctx context.Context, followerID, followeeID int) error {
+	query := `
+		INSERT INTO follows(follower_id, followee_id) VALUES($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+	_, err := fr.db.ExecContext(ctx, query, followerID, followeeID)
+	return err
+}
+
+func (fr *followRepo) Unfollow(ctx context.Context, followerID, followeeID int) error {
+	query := `DELETE FROM follows WHERE follower_id=$1 AND followee_id=$2`
+	return execAffected(ctx, fr.db, query, followerID, followeeID)
+}
+
+func (fr *followRepo) IsFollowing(ctx context.Context, followerID, followeeID int) (bool, error) {
+	var result bool
+
+	query := `SELECT EXISTS(SELECT 1 FROM follows WHERE follower_id=$1 AND followee_id=$2)`
+	err := fr.db.QueryRowContext(ctx, query, followerID, followeeID).Scan(&result)
+	if err != nil {
+		return false, err
+	}
+
+	return result, nil
+}
+
+func (fr *followRepo) GetFollowers(ctx context.Context, param repo.GetFollowQuery) (*repo.GetAllFollowsResult, error) {
+	return fr.getUsers(ctx, param, "follower_id", "followee_id")
+}
+
+func (fr *followRepo) GetFollowing(ctx context.Context, param repo.GetFollowQuery) (*repo.GetAllFollowsResult, error) {
+	return fr.getUsers(ctx, param, "followee_id", "follower_id")
+}
+
+// getUsers lists the users in the column of follows whose other side is param.UserID
+func (fr *followRepo) getUsers(ctx context.Context, param repo.GetFollowQuery, column, by string) (*repo.GetAllFollowsResult, error) {
+	result := repo.GetAllFollowsResult{
+		Users: make([]*repo.UserProfile, 0),
+	}
+
+	f := filter{}
+	f.and("f."+by+"=?", param.UserID)
+	countArgs := f.countArgs()
+
+	query := `
+		SELECT
+			u.id,
+			u.first_name,
+			coalesce(u.last_name, ''),
+			u.email,
+			u.profile_image_url
+		FROM follows f
+		INNER JOIN users u ON u.id=f.` + column + `
+		` + f.where() + `
+		ORDER BY f.created_at desc` + f.page(param.Page, param.Limit)
+
+	rows, err := fr.db.QueryContext(ctx, query, f.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var u repo.UserProfile
+		if err := rows.Scan(
+			&u.Id,
+			&u.FirstName,
+			&u.LastName,
+			&u.Email,
+			&u.ProfileImageUrl,
+		); err != nil {
+			return nil, err
+		}
+		result.Users = append(result.Users, &u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	queryCount := `SELECT count(1) FROM follows f` + f.where()
+	err = fr.db.QueryRowContext(ctx, queryCount, countArgs...).Scan(&result.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (fr *followRepo) FollowCategory(ctx context.Context, userID, categoryID int) error {
+	query := `
+		INSERT INTO category_follows(user_id, category_id) VALUES($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+	_, err := fr.db.ExecContext(ctx, query, userID, categoryID)
+	return err
+}
+
+func (fr *followRepo) UnfollowCategory(ctx context.Context, userID, categoryID int) error {
+	query := `DELETE FROM category_follows WHERE user_id=$1 AND category_id=$2`
+	return execAffected(ctx, fr.db, query, userID, categoryID)
+}
+
+func (fr *followRepo) GetFollowedCategories(ctx context.Context, param repo.GetFollowQuery) (*repo.GetAllCategoriesResult, error) {
+	result := repo.GetAllCategoriesResult{
+		Categories: make([]*repo.Category, 0),
+	}
+
+	f := filter{}
+	f.and("cf.user_id=?", param.UserID)
+	countArgs := f.countArgs()
+
+	query := `
+		SELECT
+			c.id,
+			c.title,
+			c.created_at
+		FROM category_follows cf
+		INNER JOIN categories c ON c.id=cf.category_id
+		` + f.where() + `
+		ORDER BY cf.created_at desc` + f.page(param.Page, param.Limit)
+
+	rows, err := fr.db.QueryContext(ctx, query, f.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var c repo.Category
+		if err := rows.Scan(
+			&c.Id,
+			&c.Title,
+			&c.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		result.Categories = append(result.Categories, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	queryCount := `SELECT count(1) FROM category_follows cf` + f.where()
+	err = fr.db.QueryRowContext(ctx, queryCount, countArgs...).Scan(&result.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// execAffected runs the statement and returns sql.ErrNoRows when it matched nothing
+func execAffected(ctx context.Context, db DB, query string, args ...interface{}) error {
+	res, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
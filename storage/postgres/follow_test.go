@@ -0,0 +1,72 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/post/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFollow(t *testing.T) {
+	follower := createUser(t)
+	followee := createUser(t)
+
+	require.NoError(t, strg.Follow().Follow(ctx, follower.Id, followee.Id))
+	require.NoError(t, strg.Follow().Follow(ctx, follower.Id, followee.Id))
+
+	following, err := strg.Follow().IsFollowing(ctx, follower.Id, followee.Id)
+	require.NoError(t, err)
+	require.True(t, following)
+
+	followers, err := strg.Follow().GetFollowers(ctx, repo.GetFollowQuery{Page: 1, Limit: 10, UserID: followee.Id})
+	require.NoError(t, err)
+	require.Equal(t, 1, followers.Count)
+	require.Equal(t, follower.Id, followers.Users[0].Id)
+
+	followings, err := strg.Follow().GetFollowing(ctx, repo.GetFollowQuery{Page: 1, Limit: 10, UserID: follower.Id})
+	require.NoError(t, err)
+	require.Equal(t, 1, followings.Count)
+	require.Equal(t, followee.Id, followings.Users[0].Id)
+
+	require.NoError(t, strg.Follow().Unfollow(ctx, follower.Id, followee.Id))
+	require.Error(t, strg.Follow().Unfollow(ctx, follower.Id, followee.Id))
+
+	deleteUser(follower.Id, t)
+	deleteUser(followee.Id, t)
+}
+
+func TestGetFeed(t *testing.T) {
+	u := createUser(t)
+	first := createPost(t)
+	second := createPost(t)
+	for _, p := range []*repo.Post{first, second} {
+		_, err := strg.Post().UpdateStatus(ctx, p.Id, repo.PostStatusPublished)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, strg.Follow().FollowCategory(ctx, u.Id, first.CategoryId))
+
+	page, err := strg.Post().GetFeed(ctx, repo.GetFeedQuery{UserID: u.Id, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, second.Id, page[0].Id)
+
+	page, err = strg.Post().GetFeed(ctx, repo.GetFeedQuery{
+		UserID: u.Id,
+		Limit:  1,
+		After:  &repo.Cursor{CreatedAt: page[0].CreatedAt, Id: page[0].Id},
+	})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, first.Id, page[0].Id)
+
+	categories, err := strg.Follow().GetFollowedCategories(ctx, repo.GetFollowQuery{Page: 1, Limit: 10, UserID: u.Id})
+	require.NoError(t, err)
+	require.Equal(t, 1, categories.Count)
+
+	require.NoError(t, strg.Follow().UnfollowCategory(ctx, u.Id, first.CategoryId))
+
+	deletePost(first.Id, t)
+	deletePost(second.Id, t)
+	deleteUser(u.Id, t)
+}
@@ -1,54 +1,72 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/post/storage/repo"
 )
 
 type likeRepo struct {
-	db *sqlx.DB
+	db DB
 }
 
-func NewLike(db *sqlx.DB) repo.LikeStorageI {
+func NewLike(db DB) repo.LikeStorageI {
 	return &likeRepo{
 		db: db,
 	}
 }
 
-func (lr *likeRepo) CreateOrUpdate(l *repo.Like) error {
-	like, err := lr.Get(l.UserID, l.PostID)
-	if errors.Is(err, sql.ErrNoRows) {
-		query := `
-			INSERT INTO likes(user_id, post_id, status) 
-			VALUES($1, $2, $3) RETURNING id
-		`
-
-		_, err := lr.db.Exec(query, l.UserID, l.PostID, l.Status)
-		if err != nil {
-			return err
-		}
-	} else if like != nil {
-		if like.Status == l.Status {
-			_, err := lr.db.Exec(`DELETE FROM likes WHERE id=$1`, like.ID)
+// CreateOrUpdate toggles the user's reaction to the post: a new reaction is
+// added, the opposite one is switched and the same one is removed. The
+// upsert locks the like, so concurrent toggles of one user apply in turn
+func (lr *likeRepo) CreateOrUpdate(ctx context.Context, l *repo.Like) error {
+	return inTx(ctx, lr.db, func(tx *sqlx.Tx) error {
+		// a row comes back when the like was inserted or its status switched,
+		// told apart by xmax, which is 0 only for a row this statement inserted
+		var inserted bool
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO likes(user_id, post_id, status)
+			VALUES($1, $2, $3)
+			ON CONFLICT (post_id, user_id) DO UPDATE SET status=EXCLUDED.status
+			WHERE likes.status<>EXCLUDED.status
+			RETURNING xmax=0
+		`, l.UserID, l.PostID, l.Status).Scan(&inserted)
+		if errors.Is(err, sql.ErrNoRows) {
+			// the user already reacted the same way, so the reaction is removed
+			res, err := tx.ExecContext(ctx, `DELETE FROM likes WHERE user_id=$1 AND post_id=$2`, l.UserID, l.PostID)
 			if err != nil {
 				return err
 			}
-		} else {
-			query := `UPDATE likes SET status=$1 WHERE id=$2`
-			_, err := lr.db.Exec(query, l.Status, like.ID)
+			n, err := res.RowsAffected()
 			if err != nil {
 				return err
 			}
+			return addPostCounter(ctx, tx, l.PostID, likeCounter(l.Status), -int(n))
+		} else if err != nil {
+			return err
 		}
-	}
 
-	return nil
+		if !inserted {
+			if err := addPostCounter(ctx, tx, l.PostID, likeCounter(!l.Status), -1); err != nil {
+				return err
+			}
+		}
+		return addPostCounter(ctx, tx, l.PostID, likeCounter(l.Status), 1)
+	})
+}
+
+func likeCounter(status bool) string {
+	if status {
+		return postLikesCount
+	}
+	return postDislikesCount
 }
 
-func (cr *likeRepo) Get(userID, postID int64) (*repo.Like, error) {
+func (cr *likeRepo) Get(ctx context.Context, userID, postID int64) (*repo.Like, error) {
 	var result repo.Like
 
 	query := `
@@ -61,7 +79,7 @@ func (cr *likeRepo) Get(userID, postID int64) (*repo.Like, error) {
 		WHERE user_id=$1 AND post_id=$2
 	`
 
-	row := cr.db.QueryRow(query, userID, postID)
+	row := cr.db.QueryRowContext(ctx, query, userID, postID)
 	err := row.Scan(
 		&result.ID,
 		&result.UserID,
@@ -75,18 +93,18 @@ func (cr *likeRepo) Get(userID, postID int64) (*repo.Like, error) {
 	return &result, nil
 }
 
-func (cr *likeRepo) GetLikesDislikesCount(postID int64) (repo.LikesDislikesCountsResult, error) {
+func (cr *likeRepo) GetLikesDislikesCount(ctx context.Context, postID int64) (repo.LikesDislikesCountsResult, error) {
 	var result repo.LikesDislikesCountsResult
 
 	query := `
 		SELECT
-			COUNT(1) FILTER (WHERE status=true) as likes_count,
-			COUNT(1) FILTER (WHERE status=false) as dislikes_count
-		FROM likes
-		WHERE post_id=$1
+			likes_count,
+			dislikes_count
+		FROM posts
+		WHERE id=$1
 	`
 
-	row := cr.db.QueryRow(query, postID)
+	row := cr.db.QueryRowContext(ctx, query, postID)
 	err := row.Scan(
 		&result.LikesCount,
 		&result.DislikesCount,
@@ -97,3 +115,97 @@ func (cr *likeRepo) GetLikesDislikesCount(postID int64) (repo.LikesDislikesCount
 
 	return result, nil
 }
+
+// GetUserReactions returns the user's reactions to the posts in one query.
+// Posts the user did not react to are left out of the result
+func (lr *likeRepo) GetUserReactions(ctx context.Context, postIDs []int64, userID int64) (map[int64]string, error) {
+	result := make(map[int64]string)
+	if len(postIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT
+			post_id,
+			status
+		FROM likes
+		WHERE post_id=ANY($1) AND user_id=$2
+	`
+
+	rows, err := lr.db.QueryContext(ctx, query, pq.Array(postIDs), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			postID int64
+			status bool
+		)
+		if err := rows.Scan(&postID, &status); err != nil {
+			return nil, err
+		}
+		if status {
+			result[postID] = repo.LikeReactionLike
+		} else {
+			result[postID] = repo.LikeReactionDislike
+		}
+	}
+
+	return result, rows.Err()
+}
+
+func (lr *likeRepo) GetLikers(ctx context.Context, param repo.GetLikersQuery) (*repo.GetAllLikersResult, error) {
+	result := repo.GetAllLikersResult{
+		Users: make([]*repo.UserProfile, 0),
+	}
+
+	f := filter{}
+	f.and("l.post_id=?", param.PostID)
+	f.and("l.status=true")
+	countArgs := f.countArgs()
+
+	query := `
+		SELECT
+			u.id,
+			u.first_name,
+			coalesce(u.last_name, ''),
+			u.email,
+			u.profile_image_url
+		FROM likes l
+		INNER JOIN users u ON u.id=l.user_id
+		` + f.where() + `
+		ORDER BY l.id desc` + f.page(param.Page, param.Limit)
+
+	rows, err := lr.db.QueryContext(ctx, query, f.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var u repo.UserProfile
+		if err := rows.Scan(
+			&u.Id,
+			&u.FirstName,
+			&u.LastName,
+			&u.Email,
+			&u.ProfileImageUrl,
+		); err != nil {
+			return nil, err
+		}
+		result.Users = append(result.Users, &u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	queryCount := `SELECT count(1) FROM likes l` + f.where()
+	err = lr.db.QueryRowContext(ctx, queryCount, countArgs...).Scan(&result.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
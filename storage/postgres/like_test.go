@@ -1,6 +1,8 @@
 package postgres_test
 
 import (
+	"database/sql"
+	"sync"
 	"testing"
 
 	"github.com/post/storage/repo"
@@ -13,20 +15,20 @@ func createLike(t *testing.T) (repo.Like, error) {
 		PostID: 1,
 		Status: true,
 	}
-	err := strg.Like().CreateOrUpdate(&like)
+	err := strg.Like().CreateOrUpdate(ctx, &like)
 
 	return like, err
 }
 func TestGetLike(t *testing.T) {
 	n, err := createLike(t)
 	require.NoError(t, err)
-	note, err := strg.Like().Get(n.UserID, n.PostID)
+	note, err := strg.Like().Get(ctx, n.UserID, n.PostID)
 	require.NoError(t, err)
 	require.NotEmpty(t, note)
 }
 
 func TestCreateLike(t *testing.T) {
-	err := strg.Like().CreateOrUpdate(&repo.Like{
+	err := strg.Like().CreateOrUpdate(ctx, &repo.Like{
 		UserID: 1,
 		PostID: 1,
 		Status: true,
@@ -42,7 +44,78 @@ func TestDeleteLike(t *testing.T) {
 
 func TestGetAllInfo(t *testing.T) {
 	var result repo.LikesDislikesCountsResult
-	result, err := strg.Like().GetLikesDislikesCount(1)
+	result, err := strg.Like().GetLikesDislikesCount(ctx, 1)
 	require.NoError(t, err)
 	require.NotEmpty(t, result)
 }
+
+func TestPostLikes(t *testing.T) {
+	post := createPost(t)
+	defer deletePost(post.Id, t)
+
+	like := func(status bool) {
+		err := strg.Like().CreateOrUpdate(ctx, &repo.Like{
+			UserID: 1,
+			PostID: int64(post.Id),
+			Status: status,
+		})
+		require.NoError(t, err)
+	}
+
+	like(true)
+	reactions, err := strg.Like().GetUserReactions(ctx, []int64{int64(post.Id)}, 1)
+	require.NoError(t, err)
+	require.Equal(t, repo.LikeReactionLike, reactions[int64(post.Id)])
+
+	likers, err := strg.Like().GetLikers(ctx, repo.GetLikersQuery{
+		PostID: int64(post.Id),
+		Page:   1,
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, likers.Count)
+	require.Equal(t, 1, likers.Users[0].Id)
+
+	like(false)
+	counts, err := strg.Like().GetLikesDislikesCount(ctx, int64(post.Id))
+	require.NoError(t, err)
+	require.Equal(t, int64(0), counts.LikesCount)
+	require.Equal(t, int64(1), counts.DislikesCount)
+
+	like(false)
+	counts, err = strg.Like().GetLikesDislikesCount(ctx, int64(post.Id))
+	require.NoError(t, err)
+	require.Equal(t, repo.LikesDislikesCountsResult{}, counts)
+}
+
+func TestConcurrentLikes(t *testing.T) {
+	post := createPost(t)
+	defer deletePost(post.Id, t)
+
+	// an even number of likes by one user toggles the like off again
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- strg.Like().CreateOrUpdate(ctx, &repo.Like{
+				UserID: 1,
+				PostID: int64(post.Id),
+				Status: true,
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	counts, err := strg.Like().GetLikesDislikesCount(ctx, int64(post.Id))
+	require.NoError(t, err)
+	require.Equal(t, int64(0), counts.LikesCount)
+
+	_, err = strg.Like().Get(ctx, 1, int64(post.Id))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -1,6 +1,7 @@
 package postgres_test
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -13,7 +14,9 @@ import (
 )
 
 var (
+	conn *sqlx.DB
 	strg storage.StorageI
+	ctx  = context.Background()
 )
 
 func TestMain(m *testing.M) {
@@ -28,11 +31,12 @@ func TestMain(m *testing.M) {
 	)
 	fmt.Println(connStr)
 
-	db, err := sqlx.Open("postgres", connStr)
+	var err error
+	conn, err = sqlx.Open("postgres", connStr)
 	if err != nil {
 		log.Fatalf("failed to open connection: %v", err)
 	}
-	strg = storage.NewStoragePg(db)
+	strg = storage.NewStoragePg(conn)
 
 	os.Exit(m.Run())
 
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"github.com/post/storage/repo"
+)
+
+type postRevisionRepo struct {
+	db DB
+}
+
+func NewPostRevision(db DB) repo.PostRevisionStorageI {
+	return &postRevisionRepo{db: db}
+}
+
+const postRevisionColumns = `
+			r.id,
+			r.post_id,
+			r.revision,
+			r.editor_id,
+			r.title,
+			coalesce(r.description, ''),
+			r.content_format,
+			coalesce(r.image_url, ''),
+			r.category_id,
+			r.created_at,
+			coalesce(u.first_name, ''),
+			coalesce(u.last_name, ''),
+			coalesce(u.email, ''),
+			u.profile_image_url
+`
+
+func scanPostRevision(row interface{ Scan(...interface{}) error }) (*repo.PostRevision, error) {
+	var r repo.PostRevision
+	if err := row.Scan(
+		&r.Id,
+		&r.PostId,
+		&r.Revision,
+		&r.EditorId,
+		&r.Title,
+		&r.Description,
+		&r.ContentFormat,
+		&r.ImageUrl,
+		&r.CategoryId,
+		&r.CreatedAt,
+		&r.Editor.FirstName,
+		&r.Editor.LastName,
+		&r.Editor.Email,
+		&r.Editor.ProfileImageUrl,
+	); err != nil {
+		return nil, err
+	}
+	if r.EditorId != nil {
+		r.Editor.Id = *r.EditorId
+	}
+
+	return &r, nil
+}
+
+func (rr *postRevisionRepo) Get(ctx context.Context, postID, revision int) (*repo.PostRevision, error) {
+	query := `
+		SELECT` + postRevisionColumns + `
+		FROM post_revisions r
+		LEFT JOIN users u ON u.id=r.editor_id
+		WHERE r.post_id=$1 AND r.revision=$2
+	`
+
+	return scanPostRevision(rr.db.QueryRowContext(ctx, query, postID, revision))
+}
+
+func (rr *postRevisionRepo) GetAll(ctx context.Context, param repo.GetPostRevisionQuery) (*repo.GetAllPostRevisionsResult, error) {
+	result := repo.GetAllPostRevisionsResult{
+		Revisions: make([]*repo.PostRevision, 0),
+	}
+
+	f := filter{}
+	f.and("r.post_id=?", param.PostId)
+	countArgs := f.countArgs()
+
+	query := `
+		SELECT` + postRevisionColumns + `
+		FROM post_revisions r
+		LEFT JOIN users u ON u.id=r.editor_id
+		` + f.where() + `
+		ORDER BY r.revision desc` + f.page(param.Page, param.Limit)
+
+	rows, err := rr.db.QueryContext(ctx, query, f.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		revision, err := scanPostRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		result.Revisions = append(result.Revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	queryCount := `SELECT count(1) FROM post_revisions r` + f.where()
+	err = rr.db.QueryRowContext(ctx, queryCount, countArgs...).Scan(&result.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,38 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/bxcodec/faker/v4"
+	"github.com/post/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostRevisions(t *testing.T) {
+	p := createPost(t)
+
+	first, err := strg.PostRevision().Get(ctx, p.Id, 1)
+	require.NoError(t, err)
+	require.Equal(t, p.Title, first.Title)
+
+	title := p.Title
+	p.Title = faker.Sentence()
+	_, err = strg.Post().Update(ctx, p)
+	require.NoError(t, err)
+
+	second, err := strg.PostRevision().Get(ctx, p.Id, 2)
+	require.NoError(t, err)
+	require.Equal(t, p.Title, second.Title)
+	require.NotEqual(t, title, second.Title)
+
+	result, err := strg.PostRevision().GetAll(ctx, repo.GetPostRevisionQuery{
+		Page:   1,
+		Limit:  10,
+		PostId: p.Id,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Count)
+	require.Equal(t, 2, result.Revisions[0].Revision)
+
+	deletePost(p.Id, t)
+}
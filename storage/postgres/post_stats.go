@@ -0,0 +1,252 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/post/storage/repo"
+)
+
+type postStatsRepo struct {
+	db DB
+}
+
+func NewPostStats(db DB) repo.PostStatsStorageI {
+	return &postStatsRepo{
+		db: db,
+	}
+}
+
+// Daily stats changed together with the denormalized counters of posts
+var dailyStatColumns = map[string]string{
+	postLikesCount:    "likes",
+	postCommentsCount: "comments",
+}
+
+// addDailyStat adds n to a column of today's stats of the post. Days are in UTC.
+// The column stays at zero or above, as n is negative when a like or comment
+// is taken back, and it may have been counted on an earlier day
+func addDailyStat(ctx context.Context, tx *sqlx.Tx, postID int64, column string, n int) error {
+	query := `
+		INSERT INTO post_stats_daily(post_id, day, ` + column + `)
+		VALUES($1, (now() AT TIME ZONE 'UTC')::date, greatest($2, 0))
+		ON CONFLICT (post_id, day) DO UPDATE SET
+			` + column + `=greatest(post_stats_daily.` + column + `+$2, 0)`
+	_, err := tx.ExecContext(ctx, query, postID, n)
+	return err
+}
+
+func (sr *postStatsRepo) AddDaily(ctx context.Context, stats []*repo.PostDailyStats) error {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	// Stats of posts removed in the meantime are dropped
+	query := `
+		INSERT INTO post_stats_daily(
+			post_id,
+			day,
+			views,
+			unique_readers,
+			reads,
+			likes,
+			comments,
+			referrers
+		)
+		SELECT $1, $2::date, $3, $4, $5, $6, $7, $8::jsonb
+		WHERE EXISTS(SELECT 1 FROM posts WHERE id=$1)
+		ON CONFLICT (post_id, day) DO UPDATE SET
+			views=post_stats_daily.views+EXCLUDED.views,
+			unique_readers=post_stats_daily.unique_readers+EXCLUDED.unique_readers,
+			reads=post_stats_daily.reads+EXCLUDED.reads,
+			likes=post_stats_daily.likes+EXCLUDED.likes,
+			comments=post_stats_daily.comments+EXCLUDED.comments,
+			referrers=(
+				SELECT coalesce(jsonb_object_agg(r.key, r.total), '{}')
+				FROM (
+					SELECT key, sum(value::int) AS total
+					FROM (
+						SELECT * FROM jsonb_each_text(post_stats_daily.referrers)
+						UNION ALL
+						SELECT * FROM jsonb_each_text(EXCLUDED.referrers)
+					) a
+					GROUP BY key
+				) r
+			)
+	`
+
+	return inTx(ctx, sr.db, func(tx *sqlx.Tx) error {
+		for _, s := range stats {
+			referrers, err := json.Marshal(referrerCounts(s.Referrers))
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.ExecContext(ctx,
+				query,
+				s.PostID,
+				s.Day.Format("2006-01-02"),
+				s.Views,
+				s.UniqueReaders,
+				s.Reads,
+				s.Likes,
+				s.Comments,
+				string(referrers),
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (sr *postStatsRepo) GetDaily(ctx context.Context, postID int, from, to time.Time) ([]*repo.PostDailyStats, error) {
+	result := make([]*repo.PostDailyStats, 0)
+
+	query := `
+		SELECT
+			post_id,
+			day,
+			views,
+			unique_readers,
+			reads,
+			likes,
+			comments,
+			referrers
+		FROM post_stats_daily
+		WHERE post_id=$1 AND day BETWEEN $2::date AND $3::date
+		ORDER BY day
+	`
+
+	rows, err := sr.db.QueryContext(ctx, query, postID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			s         repo.PostDailyStats
+			referrers []byte
+		)
+		if err := rows.Scan(
+			&s.PostID,
+			&s.Day,
+			&s.Views,
+			&s.UniqueReaders,
+			&s.Reads,
+			&s.Likes,
+			&s.Comments,
+			&referrers,
+		); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(referrers, &s.Referrers); err != nil {
+			return nil, err
+		}
+		result = append(result, &s)
+	}
+
+	return result, rows.Err()
+}
+
+func (sr *postStatsRepo) GetAuthorStats(ctx context.Context, userID int, from, to time.Time, topLimit int) (*repo.AuthorStats, error) {
+	result := repo.AuthorStats{
+		TopPosts: make([]*repo.PostStatsSummary, 0),
+	}
+	result.Referrers = make(map[string]int)
+
+	fromDay, toDay := from.Format("2006-01-02"), to.Format("2006-01-02")
+
+	err := sr.db.QueryRowContext(ctx, `SELECT count(1) FROM posts WHERE user_id=$1`, userID).Scan(&result.PostsCount)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT
+			p.id,
+			p.title,
+			sum(s.views),
+			sum(s.unique_readers),
+			sum(s.reads),
+			sum(s.likes),
+			sum(s.comments)
+		FROM post_stats_daily s
+		INNER JOIN posts p ON p.id=s.post_id
+		WHERE p.user_id=$1 AND s.day BETWEEN $2::date AND $3::date
+		GROUP BY p.id, p.title
+		ORDER BY sum(s.views) desc, p.id desc
+	`
+
+	rows, err := sr.db.QueryContext(ctx, query, userID, fromDay, toDay)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var s repo.PostStatsSummary
+		if err := rows.Scan(
+			&s.PostID,
+			&s.Title,
+			&s.Views,
+			&s.UniqueReaders,
+			&s.Reads,
+			&s.Likes,
+			&s.Comments,
+		); err != nil {
+			return nil, err
+		}
+
+		result.Views += s.Views
+		result.UniqueReaders += s.UniqueReaders
+		result.Reads += s.Reads
+		result.Likes += s.Likes
+		result.Comments += s.Comments
+		if len(result.TopPosts) < topLimit {
+			result.TopPosts = append(result.TopPosts, &s)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	queryReferrers := `
+		SELECT r.key, sum(r.value::int)
+		FROM post_stats_daily s
+		INNER JOIN posts p ON p.id=s.post_id
+		CROSS JOIN jsonb_each_text(s.referrers) r
+		WHERE p.user_id=$1 AND s.day BETWEEN $2::date AND $3::date
+		GROUP BY r.key
+	`
+
+	refRows, err := sr.db.QueryContext(ctx, queryReferrers, userID, fromDay, toDay)
+	if err != nil {
+		return nil, err
+	}
+
+	defer refRows.Close()
+	for refRows.Next() {
+		var (
+			domain string
+			count  int
+		)
+		if err := refRows.Scan(&domain, &count); err != nil {
+			return nil, err
+		}
+		result.Referrers[domain] = count
+	}
+
+	return &result, refRows.Err()
+}
+
+func referrerCounts(referrers map[string]int) map[string]int {
+	if referrers == nil {
+		return map[string]int{}
+	}
+	return referrers
+}
@@ -0,0 +1,65 @@
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/post/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostStats(t *testing.T) {
+	post := createPost(t)
+	defer deletePost(post.Id, t)
+
+	day := time.Date(2022, 11, 20, 0, 0, 0, 0, time.UTC)
+	add := func() {
+		err := strg.PostStats().AddDaily(ctx, []*repo.PostDailyStats{{
+			PostID:        post.Id,
+			Day:           day,
+			Views:         2,
+			UniqueReaders: 1,
+			Reads:         1,
+			Referrers:     map[string]int{"google.com": 1},
+		}})
+		require.NoError(t, err)
+	}
+	add()
+	add()
+
+	days, err := strg.PostStats().GetDaily(ctx, post.Id, day, day)
+	require.NoError(t, err)
+	require.Len(t, days, 1)
+	require.Equal(t, 4, days[0].Views)
+	require.Equal(t, 2, days[0].Reads)
+	require.Equal(t, map[string]int{"google.com": 2}, days[0].Referrers)
+
+	stats, err := strg.PostStats().GetAuthorStats(ctx, post.UserId, day, day, 10)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, stats.Views, 4)
+	require.NotEmpty(t, stats.TopPosts)
+}
+
+func TestPostStatsUnlikeEarlierDay(t *testing.T) {
+	post := createPost(t)
+	defer deletePost(post.Id, t)
+
+	like := &repo.Like{UserID: 1, PostID: int64(post.Id), Status: true}
+	require.NoError(t, strg.Like().CreateOrUpdate(ctx, like))
+
+	// the like is moved to yesterday's stats, as if it was made then
+	_, err := conn.ExecContext(ctx, `
+		UPDATE post_stats_daily SET day=day-1 WHERE post_id=$1
+	`, post.Id)
+	require.NoError(t, err)
+
+	// liking again takes the like back
+	require.NoError(t, strg.Like().CreateOrUpdate(ctx, like))
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	days, err := strg.PostStats().GetDaily(ctx, post.Id, today.AddDate(0, 0, -1), today)
+	require.NoError(t, err)
+	for _, day := range days {
+		require.GreaterOrEqual(t, day.Likes, 0)
+	}
+}
@@ -1,17 +1,21 @@
 package postgres_test
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/bxcodec/faker/v4"
+	"github.com/post/storage/postgres"
 	"github.com/post/storage/repo"
 	"github.com/stretchr/testify/require"
 )
 
 func createPost(t *testing.T) *repo.Post {
 	n, _ := faker.RandomInt(10)
-	Post, err := strg.Post().Create(&repo.Post{
+	Post, err := strg.Post().Create(ctx, &repo.Post{
 		Title:       faker.Sentence(),
 		Description: faker.Sentence(),
 		ImageUrl:    faker.URL(),
@@ -26,13 +30,13 @@ func createPost(t *testing.T) *repo.Post {
 }
 
 func deletePost(id int, t *testing.T) {
-	err := strg.Post().Delete(id)
+	err := strg.Post().Delete(ctx, id)
 	require.NoError(t, err)
 }
 
 func TestGetPost(t *testing.T) {
 	n := createPost(t)
-	note, err := strg.Post().Get(n.Id)
+	note, err := strg.Post().Get(ctx, n.Id)
 	require.NoError(t, err)
 	require.NotEmpty(t, note)
 
@@ -56,7 +60,7 @@ func TestUpdatePost(t *testing.T) {
 	n.ImageUrl = faker.URL()
 	n.ViewsCount = num[0]
 
-	Post, err := strg.Post().Update(n)
+	Post, err := strg.Post().Update(ctx, n)
 	require.NoError(t, err)
 	require.NotEmpty(t, Post)
 
@@ -71,7 +75,7 @@ func TestDeletePost(t *testing.T) {
 func TestGetAllPost(t *testing.T) {
 	u := createPost(t)
 	n, _ := faker.RandomInt(100)
-	_, err := strg.Post().GetAll(repo.GetPostQuery{
+	_, err := strg.Post().GetAll(ctx, repo.GetPostQuery{
 		Page:  n[0],
 		Limit: n[0],
 	})
@@ -79,3 +83,319 @@ func TestGetAllPost(t *testing.T) {
 	require.NoError(t, err)
 	deletePost(u.Id, t)
 }
+
+func TestSearchPost(t *testing.T) {
+	u := createPost(t)
+	result, err := strg.Post().GetAll(ctx, repo.GetPostQuery{
+		Page:   1,
+		Limit:  10,
+		Search: u.Title,
+	})
+
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Post)
+	require.NotNil(t, result.Post[0].Highlight)
+	require.Equal(t, result.Post[0].UserId, result.Post[0].User.Id)
+	require.NotEmpty(t, result.Post[0].User.Email)
+	deletePost(u.Id, t)
+}
+
+func TestUpdatePostStatus(t *testing.T) {
+	u := createPost(t)
+	require.Equal(t, repo.PostStatusDraft, u.Status)
+	require.Nil(t, u.PublishedAt)
+
+	post, err := strg.Post().UpdateStatus(ctx, u.Id, repo.PostStatusPublished)
+	require.NoError(t, err)
+	require.Equal(t, repo.PostStatusPublished, post.Status)
+	require.NotNil(t, post.PublishedAt)
+
+	result, err := strg.Post().GetAll(ctx, repo.GetPostQuery{
+		Page:     1,
+		Limit:    10,
+		UserID:   u.UserId,
+		Statuses: []string{repo.PostStatusDraft},
+	})
+	require.NoError(t, err)
+	for _, p := range result.Post {
+		require.NotEqual(t, u.Id, p.Id)
+	}
+
+	deletePost(u.Id, t)
+}
+
+func TestPublishDuePost(t *testing.T) {
+	publishAt := time.Now().Add(-time.Minute)
+	p, err := strg.Post().Create(ctx, &repo.Post{
+		Title:      faker.Sentence(),
+		UserId:     1,
+		CategoryId: 1,
+		Status:     repo.PostStatusScheduled,
+		PublishAt:  &publishAt,
+	})
+	require.NoError(t, err)
+
+	ids, err := strg.Post().PublishDue(ctx, time.Now(), 100)
+	require.NoError(t, err)
+	require.Contains(t, ids, p.Id)
+
+	post, err := strg.Post().Get(ctx, p.Id)
+	require.NoError(t, err)
+	require.Equal(t, repo.PostStatusPublished, post.Status)
+	require.Nil(t, post.PublishAt)
+
+	deletePost(p.Id, t)
+}
+
+func TestGetPostBySlug(t *testing.T) {
+	p := createPost(t)
+	oldSlug := p.Slug
+	require.NotEmpty(t, oldSlug)
+
+	post, err := strg.Post().GetBySlug(ctx, p.UserId, oldSlug)
+	require.NoError(t, err)
+	require.Equal(t, p.Id, post.Id)
+
+	p.Title = faker.Sentence()
+	p, err = strg.Post().Update(ctx, p)
+	require.NoError(t, err)
+	require.NotEqual(t, oldSlug, p.Slug)
+
+	post, err = strg.Post().GetBySlug(ctx, p.UserId, oldSlug)
+	require.NoError(t, err)
+	require.Equal(t, p.Slug, post.Slug)
+
+	deletePost(p.Id, t)
+}
+
+func TestPostContentFormat(t *testing.T) {
+	p := createPost(t)
+	require.Equal(t, repo.ContentFormatPlain, p.ContentFormat)
+
+	p.ContentFormat = repo.ContentFormatMarkdown
+	p.Description = "# Title"
+	p.DescriptionHtml = `<h1 id="title">Title</h1>`
+	_, err := strg.Post().Update(ctx, p)
+	require.NoError(t, err)
+
+	post, err := strg.Post().Get(ctx, p.Id)
+	require.NoError(t, err)
+	require.Equal(t, repo.ContentFormatMarkdown, post.ContentFormat)
+	require.Equal(t, p.DescriptionHtml, post.DescriptionHtml)
+
+	revisions, err := strg.PostRevision().GetAll(ctx, repo.GetPostRevisionQuery{Page: 1, Limit: 1, PostId: p.Id})
+	require.NoError(t, err)
+	require.Equal(t, repo.ContentFormatMarkdown, revisions.Revisions[0].ContentFormat)
+
+	deletePost(p.Id, t)
+}
+
+func TestPostSummary(t *testing.T) {
+	p := createPost(t)
+	require.Empty(t, p.TableOfContents)
+
+	p.ReadingTime = 3
+	p.Excerpt = faker.Sentence()
+	p.TableOfContents = []*repo.PostHeading{
+		{Level: 1, Text: "Title", Id: "title"},
+		{Level: 2, Text: "Intro", Id: "intro"},
+	}
+	_, err := strg.Post().Update(ctx, p)
+	require.NoError(t, err)
+
+	post, err := strg.Post().Get(ctx, p.Id)
+	require.NoError(t, err)
+	require.Equal(t, p.ReadingTime, post.ReadingTime)
+	require.Equal(t, p.Excerpt, post.Excerpt)
+	require.Equal(t, p.TableOfContents, post.TableOfContents)
+
+	deletePost(p.Id, t)
+}
+
+func TestRecountCounters(t *testing.T) {
+	post := createPost(t)
+	defer deletePost(post.Id, t)
+
+	_, err := strg.Comment().Create(ctx, &repo.Comment{
+		PostId:      post.Id,
+		UserId:      1,
+		Description: "counted",
+	})
+	require.NoError(t, err)
+
+	got, err := strg.Post().Get(ctx, post.Id)
+	require.NoError(t, err)
+	require.Equal(t, 1, got.CommentsCount)
+
+	_, err = strg.Post().RecountCounters(ctx)
+	require.NoError(t, err)
+
+	got, err = strg.Post().Get(ctx, post.Id)
+	require.NoError(t, err)
+	require.Equal(t, 1, got.CommentsCount)
+}
+
+func TestAddViews(t *testing.T) {
+	post := createPost(t)
+	defer deletePost(post.Id, t)
+
+	err := strg.Post().AddViews(ctx, map[int]int{post.Id: 3})
+	require.NoError(t, err)
+
+	got, err := strg.Post().Get(ctx, post.Id)
+	require.NoError(t, err)
+	require.Equal(t, post.ViewsCount+3, got.ViewsCount)
+}
+
+func TestPostRankings(t *testing.T) {
+	hot := createPost(t)
+	defer deletePost(hot.Id, t)
+	cold := createPost(t)
+	defer deletePost(cold.Id, t)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	err := strg.PostStats().AddDaily(ctx, []*repo.PostDailyStats{
+		{PostID: hot.Id, Day: today, Views: 10, Likes: 2},
+		{PostID: cold.Id, Day: today.AddDate(0, 0, -10), Views: 20},
+	})
+	require.NoError(t, err)
+	require.NoError(t, strg.Post().RefreshRankings(ctx))
+
+	position := func(sort string) (int, int) {
+		result, err := strg.Post().GetAll(ctx, repo.GetPostQuery{
+			Page:     1,
+			Limit:    1000,
+			Statuses: []string{repo.PostStatusDraft},
+			Sort:     sort,
+		})
+		require.NoError(t, err)
+
+		hotAt, coldAt := -1, -1
+		for i, p := range result.Post {
+			switch p.Id {
+			case hot.Id:
+				hotAt = i
+			case cold.Id:
+				coldAt = i
+			}
+		}
+		return hotAt, coldAt
+	}
+
+	// the older views of cold decay below the fresh activity of hot
+	hotAt, coldAt := position(repo.PostSortTrending)
+	require.NotEqual(t, -1, hotAt)
+	require.Less(t, hotAt, coldAt)
+
+	// cold falls out of the week entirely
+	hotAt, coldAt = position(repo.PostSortTopWeek)
+	require.NotEqual(t, -1, hotAt)
+	require.Equal(t, -1, coldAt)
+
+	// and wins on the unweighted month
+	hotAt, coldAt = position(repo.PostSortTopMonth)
+	require.Less(t, coldAt, hotAt)
+}
+
+func TestGetRelatedCandidates(t *testing.T) {
+	source := createPost(t)
+	defer deletePost(source.Id, t)
+	tagged := createPost(t)
+	defer deletePost(tagged.Id, t)
+
+	tag := "related-" + faker.Word()
+	for _, id := range []int{source.Id, tagged.Id} {
+		_, err := strg.Tag().SetPostTags(ctx, id, []string{tag})
+		require.NoError(t, err)
+	}
+
+	_, err := strg.Post().UpdateStatus(ctx, tagged.Id, repo.PostStatusPublished)
+	require.NoError(t, err)
+
+	result, err := strg.Post().GetRelatedCandidates(ctx, source.Id, 50)
+	require.NoError(t, err)
+	require.Equal(t, source.Id, result.Source.PostID)
+	require.Equal(t, 1, result.TagPosts[tag])
+
+	var found *repo.RelatedPost
+	for _, p := range result.Candidates {
+		require.NotEqual(t, source.Id, p.PostID)
+		if p.PostID == tagged.Id {
+			found = p
+		}
+	}
+	require.NotNil(t, found)
+	require.Equal(t, []string{tag}, found.Tags)
+
+	posts, err := strg.Post().GetAll(ctx, repo.GetPostQuery{
+		Page:  1,
+		Limit: 10,
+		IDs:   []int{tagged.Id},
+	})
+	require.NoError(t, err)
+	require.Len(t, posts.Post, 1)
+	require.Equal(t, tagged.Id, posts.Post[0].Id)
+}
+
+func TestGetAllPostFilters(t *testing.T) {
+	u := createUser(t)
+	p := createPost(t)
+
+	// the post is in the category but by another user, so both filters
+	// together leave it out
+	result, err := strg.Post().GetAll(ctx, repo.GetPostQuery{
+		Page:       1,
+		Limit:      10,
+		CategoryID: p.CategoryId,
+		UserID:     u.Id,
+	})
+	require.NoError(t, err)
+	require.Empty(t, result.Post)
+	require.Zero(t, result.Count)
+
+	deletePost(p.Id, t)
+	deleteUser(u.Id, t)
+}
+
+// countingDB counts the queries the repo runs
+type countingDB struct {
+	postgres.DB
+	queries int
+}
+
+func (d *countingDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	d.queries++
+	return d.DB.ExecContext(ctx, query, args...)
+}
+
+func (d *countingDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	d.queries++
+	return d.DB.QueryContext(ctx, query, args...)
+}
+
+func (d *countingDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	d.queries++
+	return d.DB.QueryRowContext(ctx, query, args...)
+}
+
+func TestGetAllPostQueries(t *testing.T) {
+	for i := 0; i < 12; i++ {
+		post := createPost(t)
+		defer deletePost(post.Id, t)
+	}
+
+	queries := func(limit int) int {
+		db := &countingDB{DB: conn}
+		result, err := postgres.NewPost(db).GetAll(ctx, repo.GetPostQuery{
+			Page:     1,
+			Limit:    limit,
+			ViewerID: 1,
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Post)
+		return db.queries
+	}
+
+	// the authors and the viewer's state are joined, not looked up per post
+	require.Equal(t, queries(2), queries(12))
+}
@@ -1,188 +1,594 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/post/pkg/utils"
 	"github.com/post/storage/repo"
 )
 
+const maxSlugLength = 200
+
+// slugAttempts is how many slugs Create tries when posts created at the
+// same time take the one it picked
+const slugAttempts = 5
+
+const postTagsColumn = `coalesce((
+				SELECT array_agg(t.name ORDER BY t.name) FROM post_tags pt
+				INNER JOIN tags t ON t.id=pt.tag_id
+				WHERE pt.post_id=posts.id
+			), '{}')`
+
+const postClapsColumn = `(SELECT coalesce(sum(c.count), 0) FROM claps c WHERE c.post_id=posts.id)`
+
 type postRepo struct {
-	db *sqlx.DB
+	db DB
 }
 
-func NewPost(db *sqlx.DB) repo.PostStorageI {
+func NewPost(db DB) repo.PostStorageI {
 	return &postRepo{db: db}
 }
 
-func (pr *postRepo) Create(p *repo.Post) (*repo.Post, error) {
+func (pr *postRepo) Create(ctx context.Context, p *repo.Post) (*repo.Post, error) {
 	query := `
-		INSERT INTO posts(
-			title,
-			description,
-			image_url,
-			user_id,
-			category_id,
-			views_count
-		)values($1,$2,$3,$4,$5,$6)
-		RETURNING id,created_at
+		WITH inserted AS (
+			INSERT INTO posts(
+				title,
+				description,
+				image_url,
+				user_id,
+				category_id,
+				views_count,
+				status,
+				published_at,
+				publish_at,
+				slug,
+				content_format,
+				description_html,
+				reading_time_minutes,
+				excerpt,
+				table_of_contents
+			)values($1,$2,$3,$4,$5,$6,$7,CASE WHEN $7='published' THEN now() END,$8,$9,$10,$11,$12,$13,$14)
+			ON CONFLICT ON CONSTRAINT posts_user_id_slug_key DO NOTHING
+			RETURNING *
+		), revision AS (
+			INSERT INTO post_revisions(post_id, revision, editor_id, title, description, image_url, category_id, content_format)
+			SELECT id, 1, user_id, title, description, image_url, category_id, content_format FROM inserted
+		)
+		SELECT id, created_at, published_at FROM inserted
 	`
-	row := pr.db.QueryRow(
-		query,
-		p.Title,
-		p.Description,
-		p.ImageUrl,
-		p.UserId,
-		p.CategoryId,
-		p.ViewsCount,
-	)
+	if p.Status == "" {
+		p.Status = repo.PostStatusDraft
+	}
+	if p.ContentFormat == "" {
+		p.ContentFormat = repo.ContentFormatPlain
+	}
 
-	if err := row.Scan(
-		&p.Id,
-		&p.CreatedAt,
-	); err != nil {
+	toc, err := json.Marshal(tableOfContents(p.TableOfContents))
+	if err != nil {
 		return nil, err
 	}
 
-	return p, nil
-}
+	// A post created at the same time can take the slug between uniqueSlug
+	// and the insert. The insert then adds nothing, without failing the
+	// transaction, and the next free slug is tried
+	base := postSlugBase(p.Title)
+	for attempt := 0; attempt < slugAttempts; attempt++ {
+		p.Slug, err = pr.uniqueSlug(ctx, p.UserId, 0, base)
+		if err != nil {
+			return nil, err
+		}
 
-func (pr *postRepo) Get(id int) (*repo.Post, error) {
-	var Post repo.Post
+		row := pr.db.QueryRowContext(ctx,
+			query,
+			p.Title,
+			p.Description,
+			p.ImageUrl,
+			p.UserId,
+			p.CategoryId,
+			p.ViewsCount,
+			p.Status,
+			p.PublishAt,
+			p.Slug,
+			p.ContentFormat,
+			p.DescriptionHtml,
+			p.ReadingTime,
+			p.Excerpt,
+			toc,
+		)
 
-	query := `
-		SELECT 
+		err = row.Scan(
+			&p.Id,
+			&p.CreatedAt,
+			&p.PublishedAt,
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return p, nil
+	}
+
+	return nil, fmt.Errorf("no free slug for %q after %d attempts", base, slugAttempts)
+}
+
+// postColumns are the columns of a whole post read by scanPost
+const postColumns = `
 			id,
 			title,
+			slug,
 			description,
 			image_url,
 			user_id,
 			category_id,
 			views_count,
-			created_at
-		from posts
-		where id=$1
-	`
-	row := pr.db.QueryRow(query, id)
+			created_at,
+			status,
+			published_at,
+			publish_at,
+			content_format,
+			coalesce(description_html, ''),
+			reading_time_minutes,
+			excerpt,
+			table_of_contents,
+			likes_count,
+			dislikes_count,
+			comments_count,
+			bookmarks_count,
+			` + postClapsColumn + `,
+			` + postTagsColumn
+
+// rowScanner is a *sql.Row or *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPost(row rowScanner) (*repo.Post, error) {
+	var (
+		Post repo.Post
+		toc  []byte
+	)
+
 	if err := row.Scan(
 		&Post.Id,
 		&Post.Title,
+		&Post.Slug,
 		&Post.Description,
 		&Post.ImageUrl,
 		&Post.UserId,
 		&Post.CategoryId,
 		&Post.ViewsCount,
 		&Post.CreatedAt,
+		&Post.Status,
+		&Post.PublishedAt,
+		&Post.PublishAt,
+		&Post.ContentFormat,
+		&Post.DescriptionHtml,
+		&Post.ReadingTime,
+		&Post.Excerpt,
+		&toc,
+		&Post.LikesCount,
+		&Post.DislikesCount,
+		&Post.CommentsCount,
+		&Post.BookmarksCount,
+		&Post.ClapsCount,
+		pq.Array(&Post.Tags),
 	); err != nil {
 		return nil, err
 	}
+	if err := json.Unmarshal(toc, &Post.TableOfContents); err != nil {
+		return nil, err
+	}
 
 	return &Post, nil
 }
 
-func (pr *postRepo) GetAll(param repo.GetPostQuery) (*repo.GetAllPostResult, error) {
+func (pr *postRepo) Get(ctx context.Context, id int) (*repo.Post, error) {
+	query := `SELECT ` + postColumns + ` FROM posts WHERE id=$1`
+	return scanPost(pr.db.QueryRowContext(ctx, query, id))
+}
+
+// GetMany returns the posts with the ids by id, leaving out missing ones
+func (pr *postRepo) GetMany(ctx context.Context, ids []int) (map[int]*repo.Post, error) {
+	result := make(map[int]*repo.Post, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	query := `SELECT ` + postColumns + ` FROM posts WHERE id=ANY($1)`
+	rows, err := pr.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		result[post.Id] = post
+	}
+
+	return result, rows.Err()
+}
+
+func (pr *postRepo) GetAll(ctx context.Context, param repo.GetPostQuery) (*repo.GetAllPostResult, error) {
 	result := repo.GetAllPostResult{
 		Post: make([]*repo.Post, 0),
 	}
 
-	offset := (param.Page - 1) * param.Limit
-
-	limit := fmt.Sprintf(" LIMIT %d OFFSET %d ", param.Limit, offset)
-	filter := ""
+	dir := sortDirection(param.SortByDate, "desc")
 
+	f := filter{}
 	if param.CategoryID > 0 {
-		filter += fmt.Sprintf("where category_id=%d", param.CategoryID)
+		f.and("posts.category_id=?", param.CategoryID)
 	}
 	if param.UserID > 0 {
-		if filter == "" {
-			filter += fmt.Sprintf("where user_id=%d", param.UserID)
-		} else {
-			filter += fmt.Sprintf("or user_id=%d", param.UserID)
-		}
+		f.and("posts.user_id=?", param.UserID)
+	}
+	if len(param.Statuses) > 0 {
+		f.and("posts.status = ANY(?)", pq.Array(param.Statuses))
+	}
+	if param.Tag != "" {
+		f.and(`posts.id IN (
+			SELECT pt.post_id FROM post_tags pt
+			INNER JOIN tags t ON t.id=pt.tag_id
+			WHERE t.slug=?)`, param.Tag)
+	}
+	if len(param.IDs) > 0 {
+		f.and("posts.id = ANY(?)", pq.Array(param.IDs))
+	}
+	if param.ReadingListID > 0 {
+		f.and("posts.id IN (SELECT post_id FROM reading_list_items WHERE list_id=?)", param.ReadingListID)
 	}
-	if param.SortByDate == "" {
-		param.SortByDate = "desc"
+	f.after("posts", dir, param.After)
+
+	// the rankings join must come before the search function, otherwise
+	// its ON clause could not reference posts
+	rankings := ""
+	score, ranked := rankingScores[param.Sort]
+	if ranked {
+		rankings = " INNER JOIN post_rankings r ON r.post_id=posts.id"
+	}
+
+	search := ""
+	highlight := ""
+	orderBy := "ORDER BY " + byCreation("posts", dir)
+	if param.Search != "" {
+		search = ", websearch_to_tsquery('simple', " + f.bind(param.Search) + ") q"
+		f.and("posts.search_vector @@ q")
+		highlight = `,
+			ts_rank(search_vector, q) AS rank,
+			ts_headline('simple', title, q, E'` + highlightSel + `') AS title_highlight,
+			ts_headline('simple', coalesce(description, ''), q,
+				E'MaxFragments=2, MaxWords=30, MinWords=10, ` + highlightSel + `') AS description_highlight`
+		orderBy = "ORDER BY rank desc, posts.created_at desc"
+	}
+
+	switch {
+	case ranked:
+		// posts without activity in the period of the score are left out
+		f.and("r." + score + " > 0")
+		orderBy = "ORDER BY r." + score + " desc, posts.id desc"
+	case param.Sort == repo.PostSortMostViewed:
+		orderBy = "ORDER BY posts.views_count desc, posts.id desc"
+	}
+
+	// the count query shares the filter args, so the viewer and the page
+	// are bound last and only referenced by the select
+	countArgs := f.countArgs()
+	viewerArg := 0
+	if param.ViewerID > 0 {
+		f.bind(param.ViewerID)
+		viewerArg = len(f.args)
 	}
+
+	// only the listed page needs the authors, so the count query skips them
 	query := `
-		SELECT 
-			id,
-			title,
-			description,
-			image_url,
-			user_id,
-			category_id,
-			views_count,
-			created_at
-		FROM posts
-		` + filter + `
-		ORDER BY created_at ` + param.SortByDate + ` ` + limit
+		SELECT ` + postListColumns(viewerArg) + highlight + `
+		FROM posts` + postAuthorJoin + rankings + search + `
+		` + f.where() + `
+		` + orderBy + f.page(param.Page, param.Limit)
 
-	rows, err := pr.db.Query(query)
+	rows, err := pr.db.QueryContext(ctx, query, f.args...)
 	if err != nil {
 		return nil, err
 	}
 
 	defer rows.Close()
 	for rows.Next() {
-		var Post repo.Post
-		if err := rows.Scan(
-			&Post.Id,
-			&Post.Title,
-			&Post.Description,
-			&Post.ImageUrl,
-			&Post.UserId,
-			&Post.CategoryId,
-			&Post.ViewsCount,
-			&Post.CreatedAt,
-		); err != nil {
+		var (
+			Post  repo.Post
+			extra []interface{}
+		)
+		if param.Search != "" {
+			Post.Highlight = &repo.PostHighlight{}
+			extra = append(extra,
+				&Post.Highlight.Rank,
+				&Post.Highlight.Title,
+				&Post.Highlight.Description,
+			)
+		}
+		if err := scanListPost(rows, &Post, extra...); err != nil {
 			return nil, err
 		}
+		if Post.Highlight != nil {
+			Post.Highlight.Title = highlightHTML(Post.Highlight.Title)
+			Post.Highlight.Description = highlightHTML(Post.Highlight.Description)
+		}
 		result.Post = append(result.Post, &Post)
 	}
-	queryCount := `SELECT count(1) FROM posts ` + filter
-	err = pr.db.QueryRow(queryCount).Scan(&result.Count)
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if param.SkipCount {
+		return &result, nil
+	}
+
+	queryCount := `SELECT count(1) FROM posts` + rankings + search + f.where()
+	err = pr.db.QueryRowContext(ctx, queryCount, countArgs...).Scan(&result.Count)
 	if err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
-func (pr *postRepo) Update(post *repo.Post) (*repo.Post, error) {
+// GetFeed returns published posts of the authors and categories the user
+// follows, newest first, starting after param.After
+func (pr *postRepo) GetFeed(ctx context.Context, param repo.GetFeedQuery) ([]*repo.Post, error) {
+	result := make([]*repo.Post, 0)
+
+	// the user is the first arg, also read by postListColumns
+	f := filter{}
+	user := f.bind(param.UserID)
+	f.and("posts.status=?", repo.PostStatusPublished)
+	f.and(`posts.user_id IN (SELECT followee_id FROM follows WHERE follower_id=` + user + `)
+			OR posts.category_id IN (SELECT category_id FROM category_follows WHERE user_id=` + user + `)`)
+	f.after("posts", "desc", param.After)
+
+	query := `
+		SELECT ` + postListColumns(1) + `
+		FROM posts` + postAuthorJoin + `
+		` + f.where() + `
+		ORDER BY ` + byCreation("posts", "desc") + `
+		LIMIT ` + f.bind(param.Limit)
+
+	rows, err := pr.db.QueryContext(ctx, query, f.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var post repo.Post
+		if err := scanListPost(rows, &post); err != nil {
+			return nil, err
+		}
+		result = append(result, &post)
+	}
+
+	return result, rows.Err()
+}
+
+// postAuthorJoin joins the authors read by postListColumns, so listings
+// don't look up each author on their own
+const postAuthorJoin = " INNER JOIN users u ON u.id=posts.user_id"
+
+// postListColumns are the columns of post listings read by scanListPost.
+// The body is left out, listings show the excerpt instead.
+// viewerArg is the placeholder number of the caller's id, 0 for guests
+func postListColumns(viewerArg int) string {
+	bookmarked := "false"
+	if viewerArg > 0 {
+		bookmarked = fmt.Sprintf(`EXISTS(
+				SELECT 1 FROM reading_list_items i
+				INNER JOIN reading_lists l ON l.id=i.list_id
+				WHERE l.user_id=$%d AND i.post_id=posts.id
+			)`, viewerArg)
+	}
+
+	return `
+			posts.id,
+			title,
+			slug,
+			image_url,
+			user_id,
+			category_id,
+			views_count,
+			posts.created_at,
+			status,
+			published_at,
+			publish_at,
+			content_format,
+			reading_time_minutes,
+			excerpt,
+			table_of_contents,
+			likes_count,
+			dislikes_count,
+			comments_count,
+			bookmarks_count,
+			` + bookmarked + `,
+			` + postClapsColumn + `,
+			` + postTagsColumn + `,
+			u.first_name,
+			coalesce(u.last_name, ''),
+			u.email,
+			u.profile_image_url`
+}
+
+func scanListPost(rows *sql.Rows, post *repo.Post, extra ...interface{}) error {
+	var toc []byte
+	dest := []interface{}{
+		&post.Id,
+		&post.Title,
+		&post.Slug,
+		&post.ImageUrl,
+		&post.UserId,
+		&post.CategoryId,
+		&post.ViewsCount,
+		&post.CreatedAt,
+		&post.Status,
+		&post.PublishedAt,
+		&post.PublishAt,
+		&post.ContentFormat,
+		&post.ReadingTime,
+		&post.Excerpt,
+		&toc,
+		&post.LikesCount,
+		&post.DislikesCount,
+		&post.CommentsCount,
+		&post.BookmarksCount,
+		&post.Bookmarked,
+		&post.ClapsCount,
+		pq.Array(&post.Tags),
+		&post.User.FirstName,
+		&post.User.LastName,
+		&post.User.Email,
+		&post.User.ProfileImageUrl,
+	}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	post.User.Id = post.UserId
+
+	return json.Unmarshal(toc, &post.TableOfContents)
+}
+
+// Update saves the post and records the new content as its next revision.
+// The post row is locked first, so concurrent updates number their
+// revisions one after the other
+func (pr *postRepo) Update(ctx context.Context, post *repo.Post) (*repo.Post, error) {
+	var result *repo.Post
+	err := inTx(ctx, pr.db, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, `SELECT 1 FROM posts WHERE id=$1 FOR UPDATE`, post.Id)
+		if err != nil {
+			return err
+		}
+
+		result, err = (&postRepo{db: tx}).update(ctx, post)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (pr *postRepo) update(ctx context.Context, post *repo.Post) (*repo.Post, error) {
 	query := `
-		update posts set 
-			title=$1,
-			description=$2,
-			image_url=$3,
-			user_id=$4,
-			category_id=$5,
-			views_count=$6,
-			updated_at=$7
-		where id=$8
-		RETURNING created_at
+		WITH updated AS (
+			update posts set 
+				title=$1,
+				description=$2,
+				image_url=$3,
+				user_id=$4,
+				category_id=$5,
+				updated_at=$6,
+				slug=$11,
+				content_format=coalesce(nullif($12, ''), content_format),
+				description_html=$13,
+				reading_time_minutes=$14,
+				excerpt=$15,
+				table_of_contents=$16,
+				status=coalesce(nullif($8, ''), status),
+				published_at=CASE
+					WHEN $8='published' THEN coalesce(published_at, now())
+					ELSE published_at
+				END,
+				publish_at=CASE
+					WHEN $8='scheduled' THEN $9
+					WHEN $8='' THEN publish_at
+				END
+			where id=$7
+			RETURNING *
+		), revision AS (
+			INSERT INTO post_revisions(post_id, revision, editor_id, title, description, image_url, category_id, content_format)
+			SELECT
+				u.id,
+				(SELECT coalesce(max(r.revision), 0) + 1 FROM post_revisions r WHERE r.post_id=u.id),
+				$10,
+				u.title,
+				u.description,
+				u.image_url,
+				u.category_id,
+				u.content_format
+			FROM updated u
+		)
+		SELECT views_count, created_at, status, published_at, publish_at, content_format FROM updated
 	`
-	row := pr.db.QueryRow(
+	editorID := post.EditorId
+	if editorID == 0 {
+		editorID = post.UserId
+	}
+
+	slug, err := pr.renameSlug(ctx, post)
+	if err != nil {
+		return nil, err
+	}
+	post.Slug = slug
+
+	toc, err := json.Marshal(tableOfContents(post.TableOfContents))
+	if err != nil {
+		return nil, err
+	}
+
+	row := pr.db.QueryRowContext(ctx,
 		query,
 		post.Title,
 		post.Description,
 		post.ImageUrl,
 		post.UserId,
 		post.CategoryId,
-		post.ViewsCount,
 		time.Now(),
 		post.Id,
+		post.Status,
+		post.PublishAt,
+		editorID,
+		post.Slug,
+		post.ContentFormat,
+		post.DescriptionHtml,
+		post.ReadingTime,
+		post.Excerpt,
+		toc,
 	)
 	post.UpdatedAt = time.Now()
-	if err := row.Scan(&post.CreatedAt); err != nil {
+	if err := row.Scan(
+		&post.ViewsCount,
+		&post.CreatedAt,
+		&post.Status,
+		&post.PublishedAt,
+		&post.PublishAt,
+		&post.ContentFormat,
+	); err != nil {
 		return nil, err
 	}
 
 	return post, nil
 }
 
-func (ur *postRepo) Delete(id int) error {
-	res, err := ur.db.Exec("delete from posts where id=$1", id)
+// tableOfContents keeps an empty table of contents stored as [] instead of null
+func tableOfContents(headings []*repo.PostHeading) []*repo.PostHeading {
+	if headings == nil {
+		return make([]*repo.PostHeading, 0)
+	}
+	return headings
+}
+
+func (ur *postRepo) Delete(ctx context.Context, id int) error {
+	res, err := ur.db.ExecContext(ctx, "delete from posts where id=$1", id)
 	if err != nil {
 		return err
 	}
@@ -196,15 +602,197 @@ func (ur *postRepo) Delete(id int) error {
 	return nil
 }
 
-func (ur *postRepo) ViewsInc(id int) error {
-	_, err := ur.db.Exec(`UPDATE posts SET views_count=views_count+1 WHERE id=$1`, id)
+func (pr *postRepo) AddViews(ctx context.Context, views map[int]int) error {
+	if len(views) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(views))
+	counts := make([]int64, 0, len(views))
+	for id, n := range views {
+		ids = append(ids, int64(id))
+		counts = append(counts, int64(n))
+	}
+
+	query := `
+		UPDATE posts SET views_count=views_count+v.count
+		FROM unnest($1::int[], $2::int[]) AS v(id, count)
+		WHERE posts.id=v.id
+	`
+	_, err := pr.db.ExecContext(ctx, query, pq.Array(ids), pq.Array(counts))
+	return err
+}
+
+func (pr *postRepo) UpdateStatus(ctx context.Context, id int, status string) (*repo.Post, error) {
+	query := `
+		UPDATE posts SET
+			status=$1,
+			published_at=CASE
+				WHEN $1='published' THEN coalesce(published_at, now())
+				ELSE published_at
+			END,
+			publish_at=NULL,
+			updated_at=now()
+		WHERE id=$2
+		RETURNING id
+	`
+	if err := pr.db.QueryRowContext(ctx, query, status, id).Scan(&id); err != nil {
+		return nil, err
+	}
+
+	return pr.Get(ctx, id)
+}
+
+func (pr *postRepo) PublishDue(ctx context.Context, now time.Time, limit int) ([]int, error) {
+	result := make([]int, 0)
+
+	// SKIP LOCKED lets several schedulers run at once without
+	// publishing the same post twice
+	query := `
+		UPDATE posts SET
+			status='published',
+			published_at=publish_at,
+			publish_at=NULL,
+			updated_at=now()
+		WHERE id IN (
+			SELECT id FROM posts
+			WHERE status='scheduled' AND publish_at<=$1
+			ORDER BY publish_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id
+	`
+
+	rows, err := pr.db.QueryContext(ctx, query, now, limit)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		result = append(result, id)
+	}
+
+	return result, rows.Err()
+}
+
+func (pr *postRepo) GetBySlug(ctx context.Context, userID int, slug string) (*repo.Post, error) {
+	var id int
+
+	// old slugs keep resolving to the post they used to belong to
+	query := `
+		SELECT id FROM posts WHERE user_id=$1 AND slug=$2
+		UNION ALL
+		SELECT post_id FROM post_slugs WHERE user_id=$1 AND slug=$2
+		LIMIT 1
+	`
+	if err := pr.db.QueryRowContext(ctx, query, userID, slug).Scan(&id); err != nil {
+		return nil, err
+	}
+
+	return pr.Get(ctx, id)
+}
+
+func postSlugBase(title string) string {
+	base := utils.Slugify(title)
+	if base == "" {
+		base = "post"
+	}
+	if runes := []rune(base); len(runes) > maxSlugLength {
+		base = strings.TrimSuffix(string(runes[:maxSlugLength]), "-")
+	}
+	return base
+}
+
+// uniqueSlug returns base, or base with a numeric suffix, so that it is
+// not used by any other post of the user, old slugs included
+func (pr *postRepo) uniqueSlug(ctx context.Context, userID, postID int, base string) (string, error) {
+	query := `
+		SELECT slug FROM posts
+		WHERE user_id=$1 AND id<>$2 AND (slug=$3 OR slug LIKE $3 || '-%')
+		UNION
+		SELECT slug FROM post_slugs
+		WHERE user_id=$1 AND post_id<>$2 AND (slug=$3 OR slug LIKE $3 || '-%')
+	`
+	rows, err := pr.db.QueryContext(ctx, query, userID, postID, base)
+	if err != nil {
+		return "", err
+	}
+
+	defer rows.Close()
+	taken := make(map[string]bool)
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return "", err
+		}
+		taken[slug] = true
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	slug := base
+	for i := 2; taken[slug]; i++ {
+		slug = base + "-" + strconv.Itoa(i)
+	}
+
+	return slug, nil
+}
+
+// renameSlug returns the slug the post should have after an update.
+// When the title no longer matches the slug, the old one is kept as a redirect
+func (pr *postRepo) renameSlug(ctx context.Context, post *repo.Post) (string, error) {
+	var current string
+	err := pr.db.QueryRowContext(ctx, `SELECT slug FROM posts WHERE id=$1`, post.Id).Scan(&current)
+	if err != nil {
+		return "", err
+	}
+
+	base := postSlugBase(post.Title)
+	if hasSlugBase(current, base) {
+		return current, nil
+	}
+
+	slug, err := pr.uniqueSlug(ctx, post.UserId, post.Id, base)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = pr.db.ExecContext(ctx, `
+		INSERT INTO post_slugs(post_id, user_id, slug) VALUES($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`, post.Id, post.UserId, current)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = pr.db.ExecContext(ctx, `DELETE FROM post_slugs WHERE post_id=$1 AND slug=$2`, post.Id, slug)
+	if err != nil {
+		return "", err
+	}
+
+	return slug, nil
+}
+
+func hasSlugBase(slug, base string) bool {
+	if slug == base {
+		return true
+	}
+	suffix := strings.TrimPrefix(slug, base+"-")
+	if suffix == slug || suffix == "" {
+		return false
+	}
+	_, err := strconv.Atoi(suffix)
+	return err == nil
 }
 
-func (pr *postRepo) GetUserInfo(id int) (int) {
+func (pr *postRepo) GetUserInfo(ctx context.Context, id int) (int, error) {
 	var userId int
 
 	query := `
@@ -213,11 +801,11 @@ func (pr *postRepo) GetUserInfo(id int) (int) {
 		from posts
 		where id=$1
 	`
-	row := pr.db.QueryRow(query, id)
+	row := pr.db.QueryRowContext(ctx, query, id)
 	if err := row.Scan(
 		&userId,
 	); err != nil {
-		return -1
+		return 0, err
 	}
-	return userId
+	return userId, nil
 }
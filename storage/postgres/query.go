@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/post/storage/repo"
+)
+
+// filter builds the WHERE clause of a list query. Conditions are joined with
+// AND and their values are bound to numbered placeholders, never written
+// into the SQL
+type filter struct {
+	conds []string
+	args  []interface{}
+}
+
+// bind adds v to the args and returns its placeholder, for values used
+// outside of a condition or more than once
+func (f *filter) bind(v interface{}) string {
+	f.args = append(f.args, v)
+	return "$" + strconv.Itoa(len(f.args))
+}
+
+// and adds cond, binding args in order to the ? in it
+func (f *filter) and(cond string, args ...interface{}) {
+	if strings.Count(cond, "?") != len(args) {
+		panic("postgres: filter " + strconv.Quote(cond) + " got " + strconv.Itoa(len(args)) + " args")
+	}
+
+	var b strings.Builder
+	for _, arg := range args {
+		i := strings.IndexByte(cond, '?')
+		b.WriteString(cond[:i])
+		b.WriteString(f.bind(arg))
+		cond = cond[i+1:]
+	}
+	b.WriteString(cond)
+	f.conds = append(f.conds, b.String())
+}
+
+// after adds the condition on the rows after c in a listing of table ordered
+// by byCreation in direction dir. A nil cursor adds nothing
+func (f *filter) after(table, dir string, c *repo.Cursor) {
+	if c == nil {
+		return
+	}
+
+	op := ">"
+	if dir == "desc" {
+		op = "<"
+	}
+	f.and("("+table+".created_at, "+table+".id) "+op+" (?, ?)", c.CreatedAt, c.Id)
+}
+
+// where returns the WHERE clause, empty without conditions
+func (f *filter) where() string {
+	if len(f.conds) == 0 {
+		return ""
+	}
+	return " WHERE (" + strings.Join(f.conds, ") AND (") + ")"
+}
+
+// countArgs returns the args bound so far. A count query sharing the filter
+// takes them before the select binds its own args, such as the page
+func (f *filter) countArgs() []interface{} {
+	return f.args[:len(f.args):len(f.args)]
+}
+
+// page binds the LIMIT and OFFSET of a page and returns the clause
+func (f *filter) page(page, limit int) string {
+	return " LIMIT " + f.bind(limit) + " OFFSET " + f.bind((page-1)*limit)
+}
+
+// sortDirection whitelists the direction of an ORDER BY, which can't be
+// bound, falling back to def for anything but asc and desc
+func sortDirection(dir, def string) string {
+	switch strings.ToLower(dir) {
+	case "asc":
+		return "asc"
+	case "desc":
+		return "desc"
+	}
+	return def
+}
+
+// byCreation orders a listing of table the way filter.after expects. dir
+// must come from sortDirection
+func byCreation(table, dir string) string {
+	return table + ".created_at " + dir + ", " + table + ".id " + dir
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// containsPattern is an ILIKE pattern matching s anywhere in a value, with
+// the wildcards in s matched literally
+func containsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
+// highlightSel are the ts_headline options marking the matches with the
+// STX and ETX control characters, written as octal escapes of an E-string
+const highlightSel = `StartSel=\002, StopSel=\003`
+
+var highlightTags = strings.NewReplacer("\x02", "<b>", "\x03", "</b>")
+
+// highlightHTML turns a ts_headline result into HTML. The text of the post is
+// escaped first, so only the match markers become tags
+func highlightHTML(s string) string {
+	return highlightTags.Replace(html.EscapeString(s))
+}
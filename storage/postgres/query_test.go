@@ -0,0 +1,167 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/post/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		build func(f *filter)
+		where string
+		args  []interface{}
+	}{
+		{
+			name:  "empty",
+			build: func(f *filter) {},
+			where: "",
+		},
+		{
+			name: "conditions are joined with and",
+			build: func(f *filter) {
+				f.and("posts.category_id=?", 1)
+				f.and("posts.user_id=?", 2)
+			},
+			where: " WHERE (posts.category_id=$1) AND (posts.user_id=$2)",
+			args:  []interface{}{1, 2},
+		},
+		{
+			name: "or stays inside its condition",
+			build: func(f *filter) {
+				p := f.bind("%go%")
+				f.and("u.first_name ILIKE " + p + " OR u.email ILIKE " + p)
+				f.and("u.id<>?", 3)
+			},
+			where: " WHERE (u.first_name ILIKE $1 OR u.email ILIKE $1) AND (u.id<>$2)",
+			args:  []interface{}{"%go%", 3},
+		},
+		{
+			name: "values are bound, not written",
+			build: func(f *filter) {
+				f.and("t.name ILIKE ?", "' OR 1=1 --")
+			},
+			where: " WHERE (t.name ILIKE $1)",
+			args:  []interface{}{"' OR 1=1 --"},
+		},
+		{
+			name: "condition without args",
+			build: func(f *filter) {
+				f.and("c.parent_id IS NULL")
+			},
+			where: " WHERE (c.parent_id IS NULL)",
+		},
+		{
+			name: "cursor descending",
+			build: func(f *filter) {
+				f.and("posts.status=?", repo.PostStatusPublished)
+				f.after("posts", "desc", &repo.Cursor{CreatedAt: createdAt, Id: 7})
+			},
+			where: " WHERE (posts.status=$1) AND ((posts.created_at, posts.id) < ($2, $3))",
+			args:  []interface{}{repo.PostStatusPublished, createdAt, 7},
+		},
+		{
+			name: "cursor ascending",
+			build: func(f *filter) {
+				f.after("c", "asc", &repo.Cursor{CreatedAt: createdAt, Id: 7})
+			},
+			where: " WHERE ((c.created_at, c.id) > ($1, $2))",
+			args:  []interface{}{createdAt, 7},
+		},
+		{
+			name: "nil cursor",
+			build: func(f *filter) {
+				f.after("c", "asc", nil)
+			},
+			where: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := filter{}
+			tt.build(&f)
+			require.Equal(t, tt.where, f.where())
+			require.Equal(t, tt.args, f.args)
+		})
+	}
+}
+
+func TestFilterArgMismatch(t *testing.T) {
+	f := filter{}
+	require.Panics(t, func() { f.and("a=? AND b=?", 1) })
+	require.Panics(t, func() { f.and("a=?", 1, 2) })
+}
+
+func TestFilterPage(t *testing.T) {
+	f := filter{}
+	f.and("r.post_id=?", 5)
+	countArgs := f.countArgs()
+
+	require.Equal(t, " LIMIT $2 OFFSET $3", f.page(3, 10))
+	require.Equal(t, []interface{}{5, 10, 20}, f.args)
+	require.Equal(t, []interface{}{5}, countArgs)
+
+	// binding after the count args were taken must not change them
+	f.bind(6)
+	require.Equal(t, []interface{}{5}, countArgs)
+}
+
+func TestSortDirection(t *testing.T) {
+	tests := []struct {
+		dir, def, want string
+	}{
+		{"asc", "desc", "asc"},
+		{"DESC", "asc", "desc"},
+		{"", "desc", "desc"},
+		{"", "asc", "asc"},
+		{"none", "desc", "desc"},
+		{"desc; DROP TABLE users", "asc", "asc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			require.Equal(t, tt.want, sortDirection(tt.dir, tt.def))
+		})
+	}
+}
+
+func TestContainsPattern(t *testing.T) {
+	tests := []struct {
+		search, want string
+	}{
+		{"go", "%go%"},
+		{"100%", `%100\%%`},
+		{"snake_case", `%snake\_case%`},
+		{`C:\go`, `%C:\\go%`},
+		{"it's", "%it's%"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.search, func(t *testing.T) {
+			require.Equal(t, tt.want, containsPattern(tt.search))
+		})
+	}
+}
+
+func TestHighlightHTML(t *testing.T) {
+	tests := []struct {
+		headline, want string
+	}{
+		{"plain title", "plain title"},
+		{"learn \x02go\x03 fast", "learn <b>go</b> fast"},
+		{"<script>\x02alert\x03</script>", "&lt;script&gt;<b>alert</b>&lt;/script&gt;"},
+		{`"quoted" & 'single'`, "&#34;quoted&#34; &amp; &#39;single&#39;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.headline, func(t *testing.T) {
+			require.Equal(t, tt.want, highlightHTML(tt.headline))
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/post/storage/repo"
+)
+
+// rankingScores maps the ranked sorts to their post_rankings column.
+// post_rankings is a materialized view over post_stats_daily, so ranked
+// listings read precomputed scores through an index instead of
+// aggregating the stats of every post
+var rankingScores = map[string]string{
+	repo.PostSortTrending: "trending_score",
+	repo.PostSortTopWeek:  "week_score",
+	repo.PostSortTopMonth: "month_score",
+}
+
+func (pr *postRepo) RefreshRankings(ctx context.Context) error {
+	_, err := pr.db.ExecContext(ctx, "REFRESH MATERIALIZED VIEW CONCURRENTLY post_rankings")
+	return err
+}
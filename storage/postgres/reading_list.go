@@ -0,0 +1,293 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/post/storage/repo"
+)
+
+type readingListRepo struct {
+	db DB
+}
+
+func NewReadingList(db DB) repo.ReadingListStorageI {
+	return &readingListRepo{
+		db: db,
+	}
+}
+
+const readingListColumns = `
+			l.id,
+			l.user_id,
+			l.name,
+			coalesce(l.description, ''),
+			l.visibility,
+			l.is_default,
+			(SELECT count(1) FROM reading_list_items i WHERE i.list_id=l.id),
+			l.created_at,
+			l.updated_at
+`
+
+func scanReadingList(row interface{ Scan(...interface{}) error }) (*repo.ReadingList, error) {
+	var l repo.ReadingList
+	if err := row.Scan(
+		&l.Id,
+		&l.UserId,
+		&l.Name,
+		&l.Description,
+		&l.Visibility,
+		&l.IsDefault,
+		&l.PostsCount,
+		&l.CreatedAt,
+		&l.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+func (lr *readingListRepo) Create(ctx context.Context, l *repo.ReadingList) (*repo.ReadingList, error) {
+	query := `
+		INSERT INTO reading_lists(
+			user_id,
+			name,
+			description,
+			visibility
+		) VALUES($1, $2, $3, $4)
+		RETURNING id, is_default, created_at
+	`
+	if l.Visibility == "" {
+		l.Visibility = repo.ReadingListPrivate
+	}
+
+	row := lr.db.QueryRowContext(ctx, query, l.UserId, l.Name, l.Description, l.Visibility)
+	if err := row.Scan(
+		&l.Id,
+		&l.IsDefault,
+		&l.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (lr *readingListRepo) Get(ctx context.Context, id int) (*repo.ReadingList, error) {
+	query := `
+		SELECT` + readingListColumns + `
+		FROM reading_lists l
+		WHERE l.id=$1
+	`
+
+	return scanReadingList(lr.db.QueryRowContext(ctx, query, id))
+}
+
+// GetOrCreateDefault returns the "Saved" list of the user. Users
+// registered after the migration get it on first use
+func (lr *readingListRepo) GetOrCreateDefault(ctx context.Context, userID int) (*repo.ReadingList, error) {
+	query := `
+		INSERT INTO reading_lists(user_id, name, is_default)
+		VALUES($1, $2, true)
+		ON CONFLICT(user_id) WHERE is_default DO NOTHING
+	`
+	if _, err := lr.db.ExecContext(ctx, query, userID, repo.DefaultReadingListName); err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT` + readingListColumns + `
+		FROM reading_lists l
+		WHERE l.user_id=$1 AND l.is_default
+	`
+
+	return scanReadingList(lr.db.QueryRowContext(ctx, query, userID))
+}
+
+func (lr *readingListRepo) GetAll(ctx context.Context, param repo.GetReadingListQuery) (*repo.GetAllReadingListsResult, error) {
+	result := repo.GetAllReadingListsResult{
+		ReadingLists: make([]*repo.ReadingList, 0),
+	}
+
+	f := filter{}
+	f.and("l.user_id=?", param.UserId)
+	if param.PublicOnly {
+		f.and("l.visibility=?", repo.ReadingListPublic)
+	}
+	countArgs := f.countArgs()
+
+	query := `
+		SELECT` + readingListColumns + `
+		FROM reading_lists l
+		` + f.where() + `
+		ORDER BY l.is_default desc, l.created_at desc` + f.page(param.Page, param.Limit)
+
+	rows, err := lr.db.QueryContext(ctx, query, f.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		list, err := scanReadingList(rows)
+		if err != nil {
+			return nil, err
+		}
+		result.ReadingLists = append(result.ReadingLists, list)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	queryCount := `SELECT count(1) FROM reading_lists l` + f.where()
+	err = lr.db.QueryRowContext(ctx, queryCount, countArgs...).Scan(&result.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (lr *readingListRepo) Update(ctx context.Context, l *repo.ReadingList) (*repo.ReadingList, error) {
+	query := `
+		UPDATE reading_lists SET
+			name=$1,
+			description=$2,
+			visibility=coalesce(nullif($3, ''), visibility),
+			updated_at=now()
+		WHERE id=$4
+		RETURNING user_id, visibility, is_default, created_at, updated_at
+	`
+
+	row := lr.db.QueryRowContext(ctx, query, l.Name, l.Description, l.Visibility, l.Id)
+	if err := row.Scan(
+		&l.UserId,
+		&l.Visibility,
+		&l.IsDefault,
+		&l.CreatedAt,
+		&l.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (lr *readingListRepo) Delete(ctx context.Context, id int) error {
+	return inTx(ctx, lr.db, func(tx *sqlx.Tx) error {
+		// The items would go with the list anyway, removing them
+		// here tells which posts lose a bookmark
+		_, err := tx.ExecContext(ctx, `
+			WITH items AS (
+				DELETE FROM reading_list_items WHERE list_id=$1 RETURNING post_id
+			)
+			UPDATE posts SET bookmarks_count=bookmarks_count-1
+			WHERE id IN (SELECT post_id FROM items)
+		`, id)
+		if err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, "DELETE FROM reading_lists WHERE id=$1", id)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+}
+
+func (lr *readingListRepo) AddPost(ctx context.Context, listID, postID int) error {
+	return inTx(ctx, lr.db, func(tx *sqlx.Tx) error {
+		query := `
+			INSERT INTO reading_list_items(list_id, post_id) VALUES($1, $2)
+			ON CONFLICT DO NOTHING
+		`
+		res, err := tx.ExecContext(ctx, query, listID, postID)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		return addPostCounter(ctx, tx, int64(postID), postBookmarksCount, int(rows))
+	})
+}
+
+func (lr *readingListRepo) RemovePost(ctx context.Context, listID, postID int) error {
+	return inTx(ctx, lr.db, func(tx *sqlx.Tx) error {
+		res, err := tx.ExecContext(ctx, "DELETE FROM reading_list_items WHERE list_id=$1 AND post_id=$2", listID, postID)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return sql.ErrNoRows
+		}
+		return addPostCounter(ctx, tx, int64(postID), postBookmarksCount, -1)
+	})
+}
+
+// IsBookmarked reports whether the post is in any of the user's lists
+func (lr *readingListRepo) IsBookmarked(ctx context.Context, userID, postID int) (bool, error) {
+	var result bool
+
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM reading_list_items i
+			INNER JOIN reading_lists l ON l.id=i.list_id
+			WHERE l.user_id=$1 AND i.post_id=$2
+		)
+	`
+	err := lr.db.QueryRowContext(ctx, query, userID, postID).Scan(&result)
+	if err != nil {
+		return false, err
+	}
+
+	return result, nil
+}
+
+// GetBookmarked returns the posts of postIDs that are in any of the user's
+// lists in one query. Posts the user did not bookmark are left out
+func (lr *readingListRepo) GetBookmarked(ctx context.Context, userID int, postIDs []int) (map[int]bool, error) {
+	result := make(map[int]bool)
+	if len(postIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT DISTINCT i.post_id
+		FROM reading_list_items i
+		INNER JOIN reading_lists l ON l.id=i.list_id
+		WHERE l.user_id=$1 AND i.post_id=ANY($2)
+	`
+
+	rows, err := lr.db.QueryContext(ctx, query, userID, pq.Array(postIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var postID int
+		if err := rows.Scan(&postID); err != nil {
+			return nil, err
+		}
+		result[postID] = true
+	}
+
+	return result, rows.Err()
+}
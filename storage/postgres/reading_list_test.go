@@ -0,0 +1,71 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/bxcodec/faker/v4"
+	"github.com/post/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadingList(t *testing.T) {
+	u := createUser(t)
+	p := createPost(t)
+
+	saved, err := strg.ReadingList().GetOrCreateDefault(ctx, u.Id)
+	require.NoError(t, err)
+	require.True(t, saved.IsDefault)
+	require.Equal(t, repo.DefaultReadingListName, saved.Name)
+
+	again, err := strg.ReadingList().GetOrCreateDefault(ctx, u.Id)
+	require.NoError(t, err)
+	require.Equal(t, saved.Id, again.Id)
+
+	list, err := strg.ReadingList().Create(ctx, &repo.ReadingList{
+		UserId: u.Id,
+		Name:   faker.Word(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, repo.ReadingListPrivate, list.Visibility)
+
+	list.Visibility = repo.ReadingListPublic
+	list, err = strg.ReadingList().Update(ctx, list)
+	require.NoError(t, err)
+	require.Equal(t, repo.ReadingListPublic, list.Visibility)
+
+	require.NoError(t, strg.ReadingList().AddPost(ctx, list.Id, p.Id))
+	require.NoError(t, strg.ReadingList().AddPost(ctx, list.Id, p.Id))
+
+	list, err = strg.ReadingList().Get(ctx, list.Id)
+	require.NoError(t, err)
+	require.Equal(t, 1, list.PostsCount)
+
+	bookmarked, err := strg.ReadingList().IsBookmarked(ctx, u.Id, p.Id)
+	require.NoError(t, err)
+	require.True(t, bookmarked)
+
+	posts, err := strg.Post().GetAll(ctx, repo.GetPostQuery{
+		Page:          1,
+		Limit:         10,
+		ReadingListID: list.Id,
+		ViewerID:      u.Id,
+	})
+	require.NoError(t, err)
+	require.Len(t, posts.Post, 1)
+	require.True(t, posts.Post[0].Bookmarked)
+
+	public, err := strg.ReadingList().GetAll(ctx, repo.GetReadingListQuery{
+		Page:       1,
+		Limit:      10,
+		UserId:     u.Id,
+		PublicOnly: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, public.Count)
+
+	require.NoError(t, strg.ReadingList().RemovePost(ctx, list.Id, p.Id))
+	require.NoError(t, strg.ReadingList().Delete(ctx, list.Id))
+
+	deletePost(p.Id, t)
+	deleteUser(u.Id, t)
+}
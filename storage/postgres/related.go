@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"github.com/lib/pq"
+	"github.com/post/storage/repo"
+)
+
+func (pr *postRepo) GetRelatedCandidates(ctx context.Context, postID, limit int) (*repo.RelatedCandidates, error) {
+	result := repo.RelatedCandidates{
+		Source:     &repo.RelatedPost{},
+		Candidates: make([]*repo.RelatedPost, 0),
+		TagPosts:   make(map[string]int),
+	}
+
+	err := pr.db.QueryRowContext(ctx, `
+		SELECT id, category_id, title, coalesce(description, ''), `+postTagsColumn+`
+		FROM posts WHERE id=$1`, postID,
+	).Scan(
+		&result.Source.PostID,
+		&result.Source.CategoryID,
+		&result.Source.Title,
+		&result.Source.Description,
+		pq.Array(&result.Source.Tags),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// the terms of the source are matched by OR-ing its lexemes, so the
+	// search vector index finds posts sharing any of them
+	query := `
+		WITH source AS (
+			SELECT id, category_id, search_vector FROM posts WHERE id=$1
+		), likers AS (
+			SELECT user_id FROM likes WHERE post_id=$1 AND status
+		), candidates AS (
+			(SELECT p.id FROM posts p, source s
+				WHERE p.category_id=s.category_id AND p.status=$2
+				ORDER BY p.created_at DESC LIMIT $3)
+			UNION
+			(SELECT pt.post_id FROM post_tags pt
+				WHERE pt.tag_id IN (SELECT tag_id FROM post_tags WHERE post_id=$1)
+				ORDER BY pt.post_id DESC LIMIT $3)
+			UNION
+			(SELECT l.post_id FROM likes l
+				INNER JOIN likers k ON k.user_id=l.user_id
+				WHERE l.status
+				GROUP BY l.post_id ORDER BY count(1) DESC LIMIT $3)
+			UNION
+			(SELECT p.id FROM posts p, (
+					SELECT to_tsquery('simple', string_agg(quote_literal(v.lexeme), ' | ')) AS q
+					FROM source s, unnest(s.search_vector) v
+				) t
+				WHERE p.search_vector @@ t.q AND p.status=$2
+				ORDER BY ts_rank(p.search_vector, t.q) DESC LIMIT $3)
+		)
+		SELECT
+			posts.id,
+			posts.category_id,
+			posts.title,
+			coalesce(posts.description, ''),
+			` + postTagsColumn + `,
+			(SELECT count(1) FROM likes l
+				INNER JOIN likers k ON k.user_id=l.user_id
+				WHERE l.post_id=posts.id AND l.status)
+		FROM candidates c
+		INNER JOIN posts ON posts.id=c.id
+		WHERE posts.id<>$1 AND posts.status=$2`
+
+	rows, err := pr.db.QueryContext(ctx, query, postID, repo.PostStatusPublished, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p repo.RelatedPost
+		err := rows.Scan(
+			&p.PostID,
+			&p.CategoryID,
+			&p.Title,
+			&p.Description,
+			pq.Array(&p.Tags),
+			&p.CoLikes,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result.Candidates = append(result.Candidates, &p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = pr.db.QueryContext(ctx, `
+		SELECT t.name, count(1) FROM post_tags pt
+		INNER JOIN tags t ON t.id=pt.tag_id
+		INNER JOIN posts p ON p.id=pt.post_id AND p.status=$2
+		WHERE pt.tag_id IN (SELECT tag_id FROM post_tags WHERE post_id=$1)
+		GROUP BY t.name`, postID, repo.PostStatusPublished)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			name  string
+			count int
+		)
+		if err := rows.Scan(&name, &count); err != nil {
+			return nil, err
+		}
+		result.TagPosts[name] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = pr.db.QueryRowContext(ctx, "SELECT count(1) FROM posts WHERE status=$1", repo.PostStatusPublished).
+		Scan(&result.PostsCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
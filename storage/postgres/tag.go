@@ -0,0 +1,209 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/post/pkg/utils"
+	"github.com/post/storage/repo"
+)
+
+type tagRepo struct {
+	db DB
+}
+
+func NewTag(db DB) repo.TagStorageI {
+	return &tagRepo{
+		db: db,
+	}
+}
+
+func (tr *tagRepo) GetOrCreate(ctx context.Context, names []string) ([]*repo.Tag, error) {
+	result := make([]*repo.Tag, 0)
+	seen := make(map[string]bool)
+
+	query := `
+		INSERT INTO tags(name, slug) VALUES($1, $2)
+		ON CONFLICT(slug) DO UPDATE SET slug=EXCLUDED.slug
+		RETURNING id, name, slug, created_at
+	`
+
+	for _, name := range names {
+		slug := utils.Slugify(name)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+
+		var tag repo.Tag
+		err := tr.db.QueryRowContext(ctx, query, name, slug).Scan(
+			&tag.Id,
+			&tag.Name,
+			&tag.Slug,
+			&tag.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &tag)
+	}
+
+	return result, nil
+}
+
+func (tr *tagRepo) GetBySlug(ctx context.Context, slug string) (*repo.Tag, error) {
+	var result repo.Tag
+
+	query := `
+		SELECT
+			t.id,
+			t.name,
+			t.slug,
+			t.created_at,
+			(SELECT count(1) FROM post_tags pt WHERE pt.tag_id=t.id)
+		FROM tags t
+		WHERE t.slug=$1
+	`
+
+	row := tr.db.QueryRowContext(ctx, query, slug)
+	err := row.Scan(
+		&result.Id,
+		&result.Name,
+		&result.Slug,
+		&result.CreatedAt,
+		&result.PostsCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (tr *tagRepo) GetAll(ctx context.Context, param repo.GetTagQuery) (*repo.GetAllTagsResult, error) {
+	result := repo.GetAllTagsResult{
+		Tags: make([]*repo.Tag, 0),
+	}
+
+	f := filter{}
+	if param.Search != "" {
+		f.and("t.name ILIKE ?", containsPattern(param.Search))
+	}
+	countArgs := f.countArgs()
+
+	query := `
+		SELECT
+			t.id,
+			t.name,
+			t.slug,
+			t.created_at,
+			count(pt.post_id) AS posts_count
+		FROM tags t
+		LEFT JOIN post_tags pt ON pt.tag_id=t.id
+		` + f.where() + `
+		GROUP BY t.id
+		ORDER BY posts_count desc, t.name` + f.page(param.Page, param.Limit)
+
+	rows, err := tr.db.QueryContext(ctx, query, f.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var tag repo.Tag
+		if err := rows.Scan(
+			&tag.Id,
+			&tag.Name,
+			&tag.Slug,
+			&tag.CreatedAt,
+			&tag.PostsCount,
+		); err != nil {
+			return nil, err
+		}
+		result.Tags = append(result.Tags, &tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	queryCount := `SELECT count(1) FROM tags t` + f.where()
+	err = tr.db.QueryRowContext(ctx, queryCount, countArgs...).Scan(&result.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (tr *tagRepo) GetPostTags(ctx context.Context, postID int) ([]*repo.Tag, error) {
+	result := make([]*repo.Tag, 0)
+
+	query := `
+		SELECT
+			t.id,
+			t.name,
+			t.slug,
+			t.created_at
+		FROM tags t
+		INNER JOIN post_tags pt ON pt.tag_id=t.id
+		WHERE pt.post_id=$1
+		ORDER BY t.name
+	`
+
+	rows, err := tr.db.QueryContext(ctx, query, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var tag repo.Tag
+		if err := rows.Scan(
+			&tag.Id,
+			&tag.Name,
+			&tag.Slug,
+			&tag.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, &tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (tr *tagRepo) SetPostTags(ctx context.Context, postID int, names []string) ([]*repo.Tag, error) {
+	var tags []*repo.Tag
+	err := inTx(ctx, tr.db, func(tx *sqlx.Tx) error {
+		var err error
+		tags, err = (&tagRepo{db: tx}).GetOrCreate(ctx, names)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM post_tags WHERE post_id=$1`, postID)
+		if err != nil {
+			return err
+		}
+
+		for _, tag := range tags {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO post_tags(post_id, tag_id) VALUES($1, $2)
+				ON CONFLICT DO NOTHING
+			`, postID, tag.Id)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
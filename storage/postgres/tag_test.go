@@ -0,0 +1,44 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/bxcodec/faker/v4"
+	"github.com/post/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetPostTags(t *testing.T) {
+	p := createPost(t)
+	name := faker.Word()
+
+	tags, err := strg.Tag().SetPostTags(ctx, p.Id, []string{name, name})
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+
+	postTags, err := strg.Tag().GetPostTags(ctx, p.Id)
+	require.NoError(t, err)
+	require.Len(t, postTags, 1)
+
+	tag, err := strg.Tag().GetBySlug(ctx, tags[0].Slug)
+	require.NoError(t, err)
+	require.Equal(t, 1, tag.PostsCount)
+
+	result, err := strg.Post().GetAll(ctx, repo.GetPostQuery{
+		Page:  1,
+		Limit: 10,
+		Tag:   tag.Slug,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Post)
+
+	deletePost(p.Id, t)
+}
+
+func TestGetAllTags(t *testing.T) {
+	_, err := strg.Tag().GetAll(ctx, repo.GetTagQuery{
+		Page:  1,
+		Limit: 10,
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,55 @@
+package postgres_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/bxcodec/faker/v4"
+	"github.com/post/storage"
+	"github.com/post/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTxRollback(t *testing.T) {
+	errAbort := errors.New("abort")
+
+	var userID int
+	err := strg.WithTx(ctx, func(tx storage.StorageI) error {
+		user, err := tx.User().Create(ctx, &repo.User{
+			FirstName: faker.FirstName(),
+			Email:     faker.Email(),
+			UserName:  faker.Username(),
+			Type:      "user",
+		})
+		require.NoError(t, err)
+		userID = user.Id
+
+		// the repo's own transaction joins the outer one
+		_, err = tx.ReadingList().GetOrCreateDefault(ctx, user.Id)
+		require.NoError(t, err)
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+
+	_, err = strg.User().Get(ctx, userID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestWithTxCommit(t *testing.T) {
+	post := createPost(t)
+	defer deletePost(post.Id, t)
+
+	err := strg.WithTx(ctx, func(tx storage.StorageI) error {
+		if _, err := tx.Tag().SetPostTags(ctx, post.Id, []string{"tx"}); err != nil {
+			return err
+		}
+		return tx.Like().CreateOrUpdate(ctx, &repo.Like{UserID: 1, PostID: int64(post.Id), Status: true})
+	})
+	require.NoError(t, err)
+
+	result, err := strg.Post().Get(ctx, post.Id)
+	require.NoError(t, err)
+	require.Equal(t, []string{"tx"}, result.Tags)
+	require.Equal(t, int64(1), result.LikesCount)
+}
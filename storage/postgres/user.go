@@ -1,22 +1,23 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
-	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/post/storage/repo"
 )
 
 type userRepo struct {
-	db *sqlx.DB
+	db DB
 }
 
-func NewUser(db *sqlx.DB) repo.UserStorageI {
+func NewUser(db DB) repo.UserStorageI {
 	return &userRepo{db: db}
 }
 
-func (ur *userRepo) Create(u *repo.User) (*repo.User, error) {
+func (ur *userRepo) Create(ctx context.Context, u *repo.User) (*repo.User, error) {
 	query := `
 		INSERT INTO users(
 			first_name,
@@ -32,7 +33,7 @@ func (ur *userRepo) Create(u *repo.User) (*repo.User, error) {
 		RETURNING id,created_at
 	`
 
-	row := ur.db.QueryRow(
+	row := ur.db.QueryRowContext(ctx,
 		query,
 		u.FirstName,
 		u.LastName,
@@ -56,7 +57,7 @@ func (ur *userRepo) Create(u *repo.User) (*repo.User, error) {
 	return u, nil
 }
 
-func (ur *userRepo) Get(id int) (*repo.User, error) {
+func (ur *userRepo) Get(ctx context.Context, id int) (*repo.User, error) {
 	var user repo.User
 
 	query := `
@@ -75,7 +76,7 @@ func (ur *userRepo) Get(id int) (*repo.User, error) {
 		from users
 		where id=$1
 	`
-	row := ur.db.QueryRow(query, id)
+	row := ur.db.QueryRowContext(ctx, query, id)
 	if err := row.Scan(
 		&user.Id,
 		&user.FirstName,
@@ -95,25 +96,24 @@ func (ur *userRepo) Get(id int) (*repo.User, error) {
 	return &user, nil
 }
 
-func (ur *userRepo) GetAll(param repo.GetUserQuery) (*repo.GetAllUsersResult, error) {
+func (ur *userRepo) GetAll(ctx context.Context, param repo.GetUserQuery) (*repo.GetAllUsersResult, error) {
 	result := repo.GetAllUsersResult{
 		Users: make([]*repo.User, 0),
 	}
 
-	offset := (param.Page - 1) * param.Limit
+	dir := sortDirection(param.SortByDate, "desc")
 
-	limit := fmt.Sprintf(" LIMIT %d OFFSET %d ", param.Limit, offset)
-	filter := "WHERE true"
+	f := filter{}
 	if param.Search != "" {
-		str := "%" + param.Search + "%"
-		filter += fmt.Sprintf(` 
-			and first_name ILIKE '%s' OR last_name ILIKE '%s' OR email ILIKE '%s' 
-		OR username ILIKE '%s' OR phone_number ILIKE '%s'`, str, str, str, str, str)
-	}
-
-	if param.SortByDate == "" {
-		param.SortByDate = "desc"
+		p := f.bind(containsPattern(param.Search))
+		f.and("users.first_name ILIKE " + p +
+			" OR users.last_name ILIKE " + p +
+			" OR users.email ILIKE " + p +
+			" OR users.username ILIKE " + p +
+			" OR users.phone_number ILIKE " + p)
 	}
+	f.after("users", dir, param.After)
+	countArgs := f.countArgs()
 
 	query := `
 		SELECT 
@@ -129,10 +129,10 @@ func (ur *userRepo) GetAll(param repo.GetUserQuery) (*repo.GetAllUsersResult, er
 			type,
 			created_at
 		FROM users
-		` + filter + `
-		ORDER BY created_at ` + param.SortByDate + ` ` + limit
+		` + f.where() + `
+		ORDER BY ` + byCreation("users", dir) + f.page(param.Page, param.Limit)
 
-	rows, err := ur.db.Query(query)
+	rows, err := ur.db.QueryContext(ctx, query, f.args...)
 	if err != nil {
 		return nil, err
 	}
@@ -157,16 +157,22 @@ func (ur *userRepo) GetAll(param repo.GetUserQuery) (*repo.GetAllUsersResult, er
 		}
 		result.Users = append(result.Users, &usr)
 	}
-	queryCount := `SELECT count(1) FROM users ` + filter
-	err = ur.db.QueryRow(queryCount).Scan(&result.Count)
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if param.SkipCount {
+		return &result, nil
+	}
+
+	queryCount := `SELECT count(1) FROM users` + f.where()
+	err = ur.db.QueryRowContext(ctx, queryCount, countArgs...).Scan(&result.Count)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Println(result)
 	return &result, nil
 }
 
-func (ur *userRepo) Update(usr *repo.User) (*repo.User, error) {
+func (ur *userRepo) Update(ctx context.Context, usr *repo.User) (*repo.User, error) {
 	query := `
 		update users set 
 			first_name=$1,
@@ -181,7 +187,7 @@ func (ur *userRepo) Update(usr *repo.User) (*repo.User, error) {
 		where id=$10
 		returning created_at
 	`
-	row := ur.db.QueryRow(
+	row := ur.db.QueryRowContext(ctx,
 		query,
 		usr.FirstName,
 		usr.LastName,
@@ -201,8 +207,8 @@ func (ur *userRepo) Update(usr *repo.User) (*repo.User, error) {
 	return usr, nil
 }
 
-func (ur *userRepo) Delete(id int) error {
-	res, err := ur.db.Exec("delete from users where id=$1", id)
+func (ur *userRepo) Delete(ctx context.Context, id int) error {
+	res, err := ur.db.ExecContext(ctx, "delete from users where id=$1", id)
 	if err != nil {
 		return err
 	}
@@ -216,7 +222,7 @@ func (ur *userRepo) Delete(id int) error {
 	return nil
 }
 
-func (ur *userRepo) GetByEmail(email string) (*repo.User, error) {
+func (ur *userRepo) GetByEmail(ctx context.Context, email string) (*repo.User, error) {
 	var result repo.User
 
 	query := `
@@ -236,7 +242,7 @@ func (ur *userRepo) GetByEmail(email string) (*repo.User, error) {
 		WHERE email=$1
 	`
 
-	row := ur.db.QueryRow(query, email)
+	row := ur.db.QueryRowContext(ctx, query, email)
 	err := row.Scan(
 		&result.Id,
 		&result.FirstName,
@@ -257,10 +263,49 @@ func (ur *userRepo) GetByEmail(email string) (*repo.User, error) {
 	return &result, nil
 }
 
-func (ur *userRepo) UpdatePassword(req *repo.UpdatePassword) error {
+func (ur *userRepo) GetByUsername(ctx context.Context, username string) (*repo.User, error) {
+	var result repo.User
+
+	query := `
+		SELECT
+			id,
+			first_name,
+			last_name,
+			phone_number,
+			email,
+			gender,
+			username,
+			profile_image_url,
+			type,
+			created_at
+		FROM users
+		WHERE username=$1
+	`
+
+	row := ur.db.QueryRowContext(ctx, query, username)
+	err := row.Scan(
+		&result.Id,
+		&result.FirstName,
+		&result.LastName,
+		&result.PhoneNumber,
+		&result.Email,
+		&result.Gender,
+		&result.UserName,
+		&result.ProfileImageUrl,
+		&result.Type,
+		&result.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (ur *userRepo) UpdatePassword(ctx context.Context, req *repo.UpdatePassword) error {
 	query := `UPDATE users SET password=$1 WHERE id=$2`
 
-	_, err := ur.db.Exec(query, req.Password, req.UserID)
+	_, err := ur.db.ExecContext(ctx, query, req.Password, req.UserID)
 	if err != nil {
 		return err
 	}
@@ -268,7 +313,7 @@ func (ur *userRepo) UpdatePassword(req *repo.UpdatePassword) error {
 	return nil
 }
 
-func (ur *userRepo) CheckInfo(email, username string) (*repo.User, error) {
+func (ur *userRepo) CheckInfo(ctx context.Context, email, username string) (*repo.User, error) {
 	var result repo.User
 
 	query := `
@@ -282,7 +327,7 @@ func (ur *userRepo) CheckInfo(email, username string) (*repo.User, error) {
 		WHERE email=$1 or username=$2
 	`
 
-	row := ur.db.QueryRow(query, email, username)
+	row := ur.db.QueryRowContext(ctx, query, email, username)
 	err := row.Scan(
 		&result.ProfileImageUrl,
 	)
@@ -293,7 +338,7 @@ func (ur *userRepo) CheckInfo(email, username string) (*repo.User, error) {
 	return &result, nil
 }
 
-func (ur *userRepo) GetUserProfileInfo(usrId int) (*repo.User, error) {
+func (ur *userRepo) GetUserProfileInfo(ctx context.Context, usrId int) (*repo.User, error) {
 	var result repo.User
 
 	query := `
@@ -306,7 +351,7 @@ func (ur *userRepo) GetUserProfileInfo(usrId int) (*repo.User, error) {
 		WHERE id=$1
 	`
 
-	row := ur.db.QueryRow(query, usrId)
+	row := ur.db.QueryRowContext(ctx, query, usrId)
 	err := row.Scan(
 		&result.FirstName,
 		&result.LastName,
@@ -320,3 +365,43 @@ func (ur *userRepo) GetUserProfileInfo(usrId int) (*repo.User, error) {
 
 	return &result, nil
 }
+
+func (ur *userRepo) GetUserProfiles(ctx context.Context, ids []int) (map[int]*repo.User, error) {
+	result := make(map[int]*repo.User, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT
+			id,
+			first_name,
+			last_name,
+			email,
+			profile_image_url
+		FROM users
+		WHERE id=ANY($1)
+	`
+
+	rows, err := ur.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var user repo.User
+		if err := rows.Scan(
+			&user.Id,
+			&user.FirstName,
+			&user.LastName,
+			&user.Email,
+			&user.ProfileImageUrl,
+		); err != nil {
+			return nil, err
+		}
+		result[user.Id] = &user
+	}
+
+	return result, rows.Err()
+}
@@ -9,7 +9,7 @@ import (
 )
 
 func createUser(t *testing.T) *repo.User {
-	User, err := strg.User().Create(&repo.User{
+	User, err := strg.User().Create(ctx, &repo.User{
 		FirstName: faker.FirstName(),
 		LastName:  faker.LastName(),
 		Email:     faker.Email(),
@@ -22,13 +22,13 @@ func createUser(t *testing.T) *repo.User {
 }
 
 func deleteUser(id int, t *testing.T) {
-	err := strg.User().Delete(id)
+	err := strg.User().Delete(ctx, id)
 	require.NoError(t, err)
 }
 
 func TestGetUser(t *testing.T) {
 	n := createUser(t)
-	note, err := strg.User().Get(n.Id)
+	note, err := strg.User().Get(ctx, n.Id)
 	require.NoError(t, err)
 	require.NotEmpty(t, note)
 
@@ -48,7 +48,7 @@ func TestUpdateUser(t *testing.T) {
 	n.UserName = faker.Username()
 	n.Type = "user"
 
-	User, err := strg.User().Update(n)
+	User, err := strg.User().Update(ctx, n)
 	require.NoError(t, err)
 	require.NotEmpty(t, User)
 
@@ -63,7 +63,7 @@ func TestDeleteUser(t *testing.T) {
 func TestGetAllUser(t *testing.T) {
 	u := createUser(t)
 	n, _ := faker.RandomInt(100)
-	_, err := strg.User().GetAll(repo.GetUserQuery{
+	_, err := strg.User().GetAll(ctx, repo.GetUserQuery{
 		Page:  n[0],
 		Limit: n[0],
 	})
@@ -71,3 +71,20 @@ func TestGetAllUser(t *testing.T) {
 	require.NoError(t, err)
 	deleteUser(u.Id, t)
 }
+
+func TestSearchUserLiteral(t *testing.T) {
+	u := createUser(t)
+
+	for _, search := range []string{"' OR '1'='1", "%"} {
+		result, err := strg.User().GetAll(ctx, repo.GetUserQuery{
+			Page:       1,
+			Limit:      10,
+			Search:     search,
+			SortByDate: "none",
+		})
+		require.NoError(t, err)
+		require.Empty(t, result.Users, search)
+	}
+
+	deleteUser(u.Id, t)
+}
@@ -1,6 +1,9 @@
 package repo
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type Category struct {
 	Id        int
@@ -9,17 +12,19 @@ type Category struct {
 }
 
 type CategoryStorageI interface {
-	Create(u *Category) (*Category, error)
-	Get(id int) (*Category, error)
-	GetAll(param GetCategoryQuery) (*GetAllCategoriesResult, error)
-	Update(category *Category) (*Category, error)
-	Delete(id int) error
+	Create(ctx context.Context, u *Category) (*Category, error)
+	Get(ctx context.Context, id int) (*Category, error)
+	GetAll(ctx context.Context, param GetCategoryQuery) (*GetAllCategoriesResult, error)
+	Update(ctx context.Context, category *Category) (*Category, error)
+	Delete(ctx context.Context, id int) error
 }
 
 type GetCategoryQuery struct {
-	Page   int `json:"page" db:"page" binding:"required" default:"1"`
-	Limit  int `json:"limit" db:"limit" binding:"required" default:"10"`
-	Search string `json:"search"`
+	Page      int     `json:"page" db:"page" binding:"required" default:"1"`
+	Limit     int     `json:"limit" db:"limit" binding:"required" default:"10"`
+	Search    string  `json:"search"`
+	After     *Cursor `json:"-"`
+	SkipCount bool    `json:"-"`
 }
 
 type GetAllCategoriesResult struct {
@@ -0,0 +1,36 @@
+package repo
+
+import "context"
+
+// MaxClapsPerUser is how many times one user can clap for a post
+const MaxClapsPerUser = 50
+
+type Clap struct {
+	PostID int
+	UserID int
+	Count  int
+}
+
+type GetClappersQuery struct {
+	PostID int
+	Page   int
+	Limit  int
+}
+
+type Clapper struct {
+	User  UserProfile
+	Count int
+}
+
+type GetAllClappersResult struct {
+	Clappers []*Clapper
+	Count    int
+}
+
+type ClapStorageI interface {
+	// AddClaps adds the counts to the stored claps, capped at MaxClapsPerUser
+	AddClaps(ctx context.Context, claps []*Clap) error
+	Get(ctx context.Context, postID, userID int) (int, error)
+	GetTotal(ctx context.Context, postID int) (int, error)
+	GetClappers(ctx context.Context, param GetClappersQuery) (*GetAllClappersResult, error)
+}
@@ -1,17 +1,25 @@
 package repo
 
 import (
+	"context"
 	"time"
 )
 
 type GetCommentQuery struct {
-	Page       int    `json:"page" db:"page" binding:"required" default:"1"`
-	Limit      int    `json:"limit" db:"limit" binding:"required" default:"10"`
-	PostId     int    `json:"post_id" db:"post_id"`
-	UserId     int    `json:"user_id" db:"user_id"`
-	SortByDate string `json:"sort_by_date" enums:"asc,desc" default:"desc"`
+	Page       int     `json:"page" db:"page" binding:"required" default:"1"`
+	Limit      int     `json:"limit" db:"limit" binding:"required" default:"10"`
+	PostId     int     `json:"post_id" db:"post_id"`
+	UserId     int     `json:"user_id" db:"user_id"`
+	ParentId   int     `json:"parent_id" db:"parent_id"`
+	RootsOnly  bool    `json:"roots_only"`
+	SortByDate string  `json:"sort_by_date" enums:"asc,desc" default:"desc"`
+	Sort       string  `json:"sort" enums:"new,top" default:"new"`
+	After      *Cursor `json:"-"`
+	SkipCount  bool    `json:"-"`
 }
 
+const CommentSortTop = "top"
+
 type GetAllCommentsResult struct {
 	Comments []*Comment
 	Count    int
@@ -25,13 +33,17 @@ type Comment struct {
 	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time   `json:"updated_at" db:"updated_at"`
 	User        UserProfile `json:"user"`
+	ParentId    *int        `json:"parent_id" db:"parent_id"`
+	ReplyCount  int         `json:"reply_count"`
+	Deleted     bool        `json:"deleted"`
 }
 
 type CommentStorageI interface {
-	Create(comment *Comment) (*Comment, error)
-	Get(id int) (*Comment, error)
-	GetAll(param GetCommentQuery) (*GetAllCommentsResult, error)
-	Update(cr *Comment) (*Comment, error)
-	GetUserInfo(id int) (int)
-	Delete(id int) error
+	Create(ctx context.Context, comment *Comment) (*Comment, error)
+	Get(ctx context.Context, id int) (*Comment, error)
+	GetAll(ctx context.Context, param GetCommentQuery) (*GetAllCommentsResult, error)
+	Update(ctx context.Context, cr *Comment) (*Comment, error)
+	GetUserInfo(ctx context.Context, id int) (int)
+	Delete(ctx context.Context, id int) error
+	GetDescendants(ctx context.Context, rootIDs []int, depth int) ([]*Comment, error)
 }
@@ -0,0 +1,29 @@
+package repo
+
+import "context"
+
+const (
+	ReactionLike  = "like"
+	ReactionLove  = "love"
+	ReactionLaugh = "laugh"
+	ReactionWow   = "wow"
+	ReactionSad   = "sad"
+	ReactionAngry = "angry"
+)
+
+type CommentReaction struct {
+	CommentID int
+	UserID    int
+	Reaction  string
+}
+
+type CommentReactions struct {
+	Counts     map[string]int
+	MyReaction string
+}
+
+type CommentReactionStorageI interface {
+	CreateOrUpdate(ctx context.Context, r *CommentReaction) error
+	Get(ctx context.Context, userID, commentID int) (*CommentReaction, error)
+	GetReactions(ctx context.Context, commentIDs []int, viewerID int) (map[int]*CommentReactions, error)
+}
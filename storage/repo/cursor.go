@@ -0,0 +1,10 @@
+package repo
+
+import "time"
+
+// Cursor points at a row in a listing ordered by creation time, with the id
+// breaking ties. Listings given one return the rows after it
+type Cursor struct {
+	CreatedAt time.Time
+	Id        int
+}
@@ -0,0 +1,25 @@
+package repo
+
+import "context"
+
+type GetFollowQuery struct {
+	Page   int `json:"page" db:"page" binding:"required" default:"1"`
+	Limit  int `json:"limit" db:"limit" binding:"required" default:"10"`
+	UserID int `json:"user_id"`
+}
+
+type GetAllFollowsResult struct {
+	Users []*UserProfile
+	Count int
+}
+
+type FollowStorageI interface {
+	Follow(ctx context.Context, followerID, followeeID int) error
+	Unfollow(ctx context.Context, followerID, followeeID int) error
+	IsFollowing(ctx context.Context, followerID, followeeID int) (bool, error)
+	GetFollowers(ctx context.Context, param GetFollowQuery) (*GetAllFollowsResult, error)
+	GetFollowing(ctx context.Context, param GetFollowQuery) (*GetAllFollowsResult, error)
+	FollowCategory(ctx context.Context, userID, categoryID int) error
+	UnfollowCategory(ctx context.Context, userID, categoryID int) error
+	GetFollowedCategories(ctx context.Context, param GetFollowQuery) (*GetAllCategoriesResult, error)
+}
@@ -1,5 +1,7 @@
 package repo
 
+import "context"
+
 type Like struct {
 	ID     int64
 	PostID int64
@@ -12,8 +14,26 @@ type LikesDislikesCountsResult struct {
 	DislikesCount int64
 }
 
+const (
+	LikeReactionLike    = "like"
+	LikeReactionDislike = "dislike"
+)
+
+type GetLikersQuery struct {
+	PostID int64
+	Page   int
+	Limit  int
+}
+
+type GetAllLikersResult struct {
+	Users []*UserProfile
+	Count int
+}
+
 type LikeStorageI interface {
-	CreateOrUpdate(l *Like) error
-	Get(userID, postID int64) (*Like, error)
-	GetLikesDislikesCount(postID int64) (LikesDislikesCountsResult, error) 
+	CreateOrUpdate(ctx context.Context, l *Like) error
+	Get(ctx context.Context, userID, postID int64) (*Like, error)
+	GetLikesDislikesCount(ctx context.Context, postID int64) (LikesDislikesCountsResult, error) 
+	GetUserReactions(ctx context.Context, postIDs []int64, userID int64) (map[int64]string, error)
+	GetLikers(ctx context.Context, param GetLikersQuery) (*GetAllLikersResult, error)
 }
\ No newline at end of file
@@ -1,13 +1,25 @@
 package repo
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type GetPostQuery struct {
-	Page       int    `json:"page" db:"page" binding:"required" default:"1"`
-	Limit      int    `json:"limit" db:"limit" binding:"required" default:"10"`
-	UserID     int    `json:"user_id"`
-	CategoryID int    `json:"post_id"`
-	SortByDate string `json:"sort_by_date" enums:"asc,desc" default:"desc"`
+	Page          int      `json:"page" db:"page" binding:"required" default:"1"`
+	Limit         int      `json:"limit" db:"limit" binding:"required" default:"10"`
+	UserID        int      `json:"user_id"`
+	CategoryID    int      `json:"post_id"`
+	Search        string   `json:"search"`
+	Tag           string   `json:"tag"`
+	Statuses      []string `json:"statuses"`
+	ReadingListID int      `json:"reading_list_id"`
+	ViewerID      int      `json:"-"`
+	IDs           []int    `json:"-"`
+	After         *Cursor  `json:"-"`
+	SkipCount     bool     `json:"-"`
+	SortByDate    string   `json:"sort_by_date" enums:"asc,desc" default:"desc"`
+	Sort          string   `json:"sort" enums:"trending,top_week,top_month,most_viewed"`
 }
 
 const (
@@ -15,22 +27,82 @@ const (
 	UserTypeUser       = "user"
 )
 
+const (
+	ContentFormatPlain    = "plain"
+	ContentFormatMarkdown = "markdown"
+)
+
+// Ranked orderings of post listings. Trending, top_week and top_month
+// only list posts with activity in their period
+const (
+	PostSortTrending   = "trending"
+	PostSortTopWeek    = "top_week"
+	PostSortTopMonth   = "top_month"
+	PostSortMostViewed = "most_viewed"
+)
+
+const (
+	PostStatusDraft     = "draft"
+	PostStatusScheduled = "scheduled"
+	PostStatusPublished = "published"
+	PostStatusUnlisted  = "unlisted"
+	PostStatusArchived  = "archived"
+)
+
+type GetFeedQuery struct {
+	UserID int
+	Limit  int
+	After  *Cursor
+}
+
 type GetAllPostResult struct {
 	Post  []*Post
 	Count int
 }
 
 type Post struct {
-	Id          int         `json:"id" db:"id"`
-	Title       string      `json:"title" db:"title"`
-	Description string      `json:"description" db:"description"`
-	ImageUrl    string      `json:"image_url" db:"image_url"`
-	UserId      int         `json:"user_id" db:"user_id"`
-	CategoryId  int         `json:"category_id" db:"category_id"`
-	UpdatedAt   time.Time   `json:"updated_at" db:"updated_at"`
-	ViewsCount  int         `json:"views_count" db:"views_count"`
-	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
-	User        UserProfile `json:"user"`
+	Id              int            `json:"id" db:"id"`
+	Title           string         `json:"title" db:"title"`
+	Slug            string         `json:"slug" db:"slug"`
+	Description     string         `json:"description" db:"description"`
+	ContentFormat   string         `json:"content_format" db:"content_format"`
+	DescriptionHtml string         `json:"description_html" db:"description_html"`
+	ReadingTime     int            `json:"reading_time_minutes" db:"reading_time_minutes"`
+	Excerpt         string         `json:"excerpt" db:"excerpt"`
+	TableOfContents []*PostHeading `json:"table_of_contents" db:"table_of_contents"`
+	ImageUrl        string         `json:"image_url" db:"image_url"`
+	UserId          int            `json:"user_id" db:"user_id"`
+	CategoryId      int            `json:"category_id" db:"category_id"`
+	UpdatedAt       time.Time      `json:"updated_at" db:"updated_at"`
+	ViewsCount      int            `json:"views_count" db:"views_count"`
+	CreatedAt       time.Time      `json:"created_at" db:"created_at"`
+	Status          string         `json:"status" db:"status"`
+	PublishedAt     *time.Time     `json:"published_at" db:"published_at"`
+	PublishAt       *time.Time     `json:"publish_at" db:"publish_at"`
+	EditorId        int            `json:"-"`
+	User            UserProfile    `json:"user"`
+	Tags            []string       `json:"tags"`
+	Bookmarked      bool           `json:"bookmarked"`
+	ClapsCount      int            `json:"claps_count"`
+	MyClaps         int            `json:"my_claps"`
+	LikesCount      int64          `json:"likes_count"`
+	DislikesCount   int64          `json:"dislikes_count"`
+	CommentsCount   int            `json:"comments_count"`
+	BookmarksCount  int            `json:"bookmarks_count"`
+	MyReaction      string         `json:"my_reaction"`
+	Highlight       *PostHighlight `json:"highlight"`
+}
+
+type PostHighlight struct {
+	Title       string
+	Description string
+	Rank        float64
+}
+
+type PostHeading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	Id    string `json:"id"`
 }
 
 type UserProfile struct {
@@ -42,11 +114,26 @@ type UserProfile struct {
 }
 
 type PostStorageI interface {
-	Create(p *Post) (*Post, error)
-	Get(id int) (*Post, error)
-	GetAll(param GetPostQuery) (*GetAllPostResult, error)
-	Update(usr *Post) (*Post, error)
-	Delete(id int) error
-	GetUserInfo(id int) (int)
-	ViewsInc(id int) error
+	Create(ctx context.Context, p *Post) (*Post, error)
+	Get(ctx context.Context, id int) (*Post, error)
+	// GetMany returns the posts with the ids by id, leaving out missing ones
+	GetMany(ctx context.Context, ids []int) (map[int]*Post, error)
+	GetAll(ctx context.Context, param GetPostQuery) (*GetAllPostResult, error)
+	Update(ctx context.Context, usr *Post) (*Post, error)
+	Delete(ctx context.Context, id int) error
+	GetUserInfo(ctx context.Context, id int) (int, error)
+	// AddViews adds the counts to views_count of the posts, keyed by post id
+	AddViews(ctx context.Context, views map[int]int) error
+	UpdateStatus(ctx context.Context, id int, status string) (*Post, error)
+	PublishDue(ctx context.Context, now time.Time, limit int) ([]int, error)
+	GetFeed(ctx context.Context, param GetFeedQuery) ([]*Post, error)
+	GetBySlug(ctx context.Context, userID int, slug string) (*Post, error)
+	// RecountCounters rebuilds the denormalized counters and returns
+	// how many posts had drifted
+	RecountCounters(ctx context.Context) (int64, error)
+	// RefreshRankings recomputes the scores behind the ranked sorts
+	RefreshRankings(ctx context.Context) error
+	// GetRelatedCandidates returns published posts that share the category,
+	// tags, terms or likers of the post, up to limit from each of them
+	GetRelatedCandidates(ctx context.Context, postID, limit int) (*RelatedCandidates, error)
 }
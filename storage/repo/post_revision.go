@@ -0,0 +1,36 @@
+package repo
+
+import (
+	"context"
+	"time"
+)
+
+type PostRevision struct {
+	Id            int
+	PostId        int
+	Revision      int
+	EditorId      *int
+	Title         string
+	Description   string
+	ContentFormat string
+	ImageUrl      string
+	CategoryId    int
+	CreatedAt     time.Time
+	Editor        UserProfile
+}
+
+type GetPostRevisionQuery struct {
+	Page   int `json:"page" db:"page" binding:"required" default:"1"`
+	Limit  int `json:"limit" db:"limit" binding:"required" default:"10"`
+	PostId int `json:"post_id"`
+}
+
+type GetAllPostRevisionsResult struct {
+	Revisions []*PostRevision
+	Count     int
+}
+
+type PostRevisionStorageI interface {
+	Get(ctx context.Context, postID, revision int) (*PostRevision, error)
+	GetAll(ctx context.Context, param GetPostRevisionQuery) (*GetAllPostRevisionsResult, error)
+}
@@ -0,0 +1,46 @@
+package repo
+
+import (
+	"context"
+	"time"
+)
+
+type PostDailyStats struct {
+	PostID        int
+	Day           time.Time
+	Views         int
+	UniqueReaders int
+	Reads         int
+	Likes         int
+	Comments      int
+	Referrers     map[string]int
+}
+
+type PostStatsTotals struct {
+	Views         int
+	UniqueReaders int
+	Reads         int
+	Likes         int
+	Comments      int
+	Referrers     map[string]int
+}
+
+type PostStatsSummary struct {
+	PostID int
+	Title  string
+	PostStatsTotals
+}
+
+type AuthorStats struct {
+	PostsCount int
+	PostStatsTotals
+	TopPosts []*PostStatsSummary
+}
+
+type PostStatsStorageI interface {
+	// AddDaily adds the stats to the stored ones of the same post and day
+	AddDaily(ctx context.Context, stats []*PostDailyStats) error
+	// GetDaily returns the stats of the days in [from, to] that have any
+	GetDaily(ctx context.Context, postID int, from, to time.Time) ([]*PostDailyStats, error)
+	GetAuthorStats(ctx context.Context, userID int, from, to time.Time, topLimit int) (*AuthorStats, error)
+}
@@ -0,0 +1,51 @@
+package repo
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	ReadingListPublic  = "public"
+	ReadingListPrivate = "private"
+
+	DefaultReadingListName = "Saved"
+)
+
+type ReadingList struct {
+	Id          int
+	UserId      int
+	Name        string
+	Description string
+	Visibility  string
+	IsDefault   bool
+	PostsCount  int
+	CreatedAt   time.Time
+	UpdatedAt   *time.Time
+}
+
+type GetReadingListQuery struct {
+	Page       int `json:"page" db:"page" binding:"required" default:"1"`
+	Limit      int `json:"limit" db:"limit" binding:"required" default:"10"`
+	UserId     int `json:"user_id"`
+	PublicOnly bool
+}
+
+type GetAllReadingListsResult struct {
+	ReadingLists []*ReadingList
+	Count        int
+}
+
+type ReadingListStorageI interface {
+	Create(ctx context.Context, l *ReadingList) (*ReadingList, error)
+	Get(ctx context.Context, id int) (*ReadingList, error)
+	GetOrCreateDefault(ctx context.Context, userID int) (*ReadingList, error)
+	GetAll(ctx context.Context, param GetReadingListQuery) (*GetAllReadingListsResult, error)
+	Update(ctx context.Context, l *ReadingList) (*ReadingList, error)
+	Delete(ctx context.Context, id int) error
+	AddPost(ctx context.Context, listID, postID int) error
+	RemovePost(ctx context.Context, listID, postID int) error
+	IsBookmarked(ctx context.Context, userID, postID int) (bool, error)
+	// GetBookmarked returns the posts of postIDs that are in any of the user's lists
+	GetBookmarked(ctx context.Context, userID int, postIDs []int) (map[int]bool, error)
+}
@@ -0,0 +1,21 @@
+package repo
+
+// RelatedPost holds what related posts are ranked by
+type RelatedPost struct {
+	PostID      int
+	CategoryID  int
+	Title       string
+	Description string
+	Tags        []string
+	// CoLikes is the number of users who liked both this post and the source
+	CoLikes int
+}
+
+type RelatedCandidates struct {
+	Source     *RelatedPost
+	Candidates []*RelatedPost
+	// TagPosts is the number of published posts per tag of the source
+	TagPosts map[string]int
+	// PostsCount is the number of published posts
+	PostsCount int
+}
@@ -0,0 +1,33 @@
+package repo
+
+import (
+	"context"
+	"time"
+)
+
+type Tag struct {
+	Id         int
+	Name       string
+	Slug       string
+	PostsCount int
+	CreatedAt  time.Time
+}
+
+type GetTagQuery struct {
+	Page   int    `json:"page" db:"page" binding:"required" default:"1"`
+	Limit  int    `json:"limit" db:"limit" binding:"required" default:"10"`
+	Search string `json:"search"`
+}
+
+type GetAllTagsResult struct {
+	Tags  []*Tag
+	Count int
+}
+
+type TagStorageI interface {
+	GetOrCreate(ctx context.Context, names []string) ([]*Tag, error)
+	GetBySlug(ctx context.Context, slug string) (*Tag, error)
+	GetAll(ctx context.Context, param GetTagQuery) (*GetAllTagsResult, error)
+	GetPostTags(ctx context.Context, postID int) ([]*Tag, error)
+	SetPostTags(ctx context.Context, postID int, names []string) ([]*Tag, error)
+}
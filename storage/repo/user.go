@@ -1,6 +1,7 @@
 package repo
 
 import (
+	"context"
 	"time"
 )
 
@@ -24,22 +25,27 @@ type UpdatePassword struct {
 }
 
 type UserStorageI interface {
-	Create(u *User) (*User, error)
-	Get(id int) (*User, error)
-	GetAll(param GetUserQuery) (*GetAllUsersResult, error)
-	Update(usr *User) (*User, error)
-	Delete(id int) error
-	GetByEmail(email string) (*User, error)
-	CheckInfo(email, username string) (*User, error)
-	UpdatePassword(req *UpdatePassword) error
-	GetUserProfileInfo(usrId int) (*User, error)
+	Create(ctx context.Context, u *User) (*User, error)
+	Get(ctx context.Context, id int) (*User, error)
+	GetAll(ctx context.Context, param GetUserQuery) (*GetAllUsersResult, error)
+	Update(ctx context.Context, usr *User) (*User, error)
+	Delete(ctx context.Context, id int) error
+	GetByEmail(ctx context.Context, email string) (*User, error)
+	GetByUsername(ctx context.Context, username string) (*User, error)
+	CheckInfo(ctx context.Context, email, username string) (*User, error)
+	UpdatePassword(ctx context.Context, req *UpdatePassword) error
+	GetUserProfileInfo(ctx context.Context, usrId int) (*User, error)
+	// GetUserProfiles returns the profiles of the users by id, leaving out missing ones
+	GetUserProfiles(ctx context.Context, ids []int) (map[int]*User, error)
 }
 
 type GetUserQuery struct {
-	Page       int    `json:"page" db:"page" binding:"required" default:"1"`
-	Limit      int    `json:"limit" db:"limit" binding:"required" default:"10"`
-	Search     string `json:"search"`
-	SortByDate string `json:"sort_by_date" enums:"asc,desc" default:"desc"`
+	Page       int     `json:"page" db:"page" binding:"required" default:"1"`
+	Limit      int     `json:"limit" db:"limit" binding:"required" default:"10"`
+	Search     string  `json:"search"`
+	SortByDate string  `json:"sort_by_date" enums:"asc,desc" default:"desc"`
+	After      *Cursor `json:"-"`
+	SkipCount  bool    `json:"-"`
 }
 
 type GetAllUsersResult struct {
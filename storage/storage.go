@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"context"
+
 	"github.com/jmoiron/sqlx"
 	"github.com/post/storage/postgres"
 	"github.com/post/storage/repo"
@@ -9,26 +11,61 @@ import (
 type StorageI interface {
 	Category() repo.CategoryStorageI
 	Comment() repo.CommentStorageI
+	CommentReaction() repo.CommentReactionStorageI
 	User() repo.UserStorageI
 	Post() repo.PostStorageI
 	Like() repo.LikeStorageI
+	Tag() repo.TagStorageI
+	PostRevision() repo.PostRevisionStorageI
+	ReadingList() repo.ReadingListStorageI
+	Follow() repo.FollowStorageI
+	Clap() repo.ClapStorageI
+	PostStats() repo.PostStatsStorageI
+
+	// WithTx runs fn with repos bound to one transaction, committed if fn
+	// returns nil and rolled back otherwise. Called on the storage given to
+	// fn, it joins that transaction
+	WithTx(ctx context.Context, fn func(StorageI) error) error
 }
 
 type storagePg struct {
+	// db is the pool transactions begin on, nil for a storage bound to one
+	db *sqlx.DB
+
 	categoryRepo repo.CategoryStorageI
 	commentRepo  repo.CommentStorageI
+	reactionRepo repo.CommentReactionStorageI
 	userRepo     repo.UserStorageI
 	postRepo     repo.PostStorageI
 	likeRepo     repo.LikeStorageI
+	tagRepo      repo.TagStorageI
+	revisionRepo repo.PostRevisionStorageI
+	listRepo     repo.ReadingListStorageI
+	followRepo   repo.FollowStorageI
+	clapRepo     repo.ClapStorageI
+	statsRepo    repo.PostStatsStorageI
 }
 
 func NewStoragePg(db *sqlx.DB) StorageI {
+	s := newStoragePg(db)
+	s.db = db
+	return s
+}
+
+func newStoragePg(db postgres.DB) *storagePg {
 	return &storagePg{
 		categoryRepo: postgres.NewCategory(db),
 		commentRepo:  postgres.NewComment(db),
+		reactionRepo: postgres.NewCommentReaction(db),
 		userRepo:     postgres.NewUser(db),
 		postRepo:     postgres.NewPost(db),
 		likeRepo:     postgres.NewLike(db),
+		tagRepo:      postgres.NewTag(db),
+		revisionRepo: postgres.NewPostRevision(db),
+		listRepo:     postgres.NewReadingList(db),
+		followRepo:   postgres.NewFollow(db),
+		clapRepo:     postgres.NewClap(db),
+		statsRepo:    postgres.NewPostStats(db),
 	}
 }
 
@@ -40,6 +77,10 @@ func (s *storagePg) Comment() repo.CommentStorageI {
 	return s.commentRepo
 }
 
+func (s *storagePg) CommentReaction() repo.CommentReactionStorageI {
+	return s.reactionRepo
+}
+
 func (s *storagePg) User() repo.UserStorageI {
 	return s.userRepo
 }
@@ -51,3 +92,37 @@ func (s *storagePg) Post() repo.PostStorageI {
 func (s *storagePg) Like() repo.LikeStorageI {
 	return s.likeRepo
 }
+
+func (s *storagePg) Tag() repo.TagStorageI {
+	return s.tagRepo
+}
+
+func (s *storagePg) PostRevision() repo.PostRevisionStorageI {
+	return s.revisionRepo
+}
+
+func (s *storagePg) ReadingList() repo.ReadingListStorageI {
+	return s.listRepo
+}
+
+func (s *storagePg) Follow() repo.FollowStorageI {
+	return s.followRepo
+}
+
+func (s *storagePg) Clap() repo.ClapStorageI {
+	return s.clapRepo
+}
+
+func (s *storagePg) PostStats() repo.PostStatsStorageI {
+	return s.statsRepo
+}
+
+func (s *storagePg) WithTx(ctx context.Context, fn func(StorageI) error) error {
+	if s.db == nil {
+		return fn(s)
+	}
+
+	return postgres.RunTx(ctx, s.db, func(tx *sqlx.Tx) error {
+		return fn(newStoragePg(tx))
+	})
+}